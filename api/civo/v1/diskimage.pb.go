@@ -0,0 +1,345 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.12
+// source: civo/v1/diskimage.proto
+
+package civov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListDiskImageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Only the images of the distribution, such as ubuntu, when set
+	Distribution string `protobuf:"bytes,1,opt,name=distribution,proto3" json:"distribution,omitempty"`
+}
+
+func (x *ListDiskImageRequest) Reset() {
+	*x = ListDiskImageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_civo_v1_diskimage_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListDiskImageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDiskImageRequest) ProtoMessage() {}
+
+func (x *ListDiskImageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_civo_v1_diskimage_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDiskImageRequest.ProtoReflect.Descriptor instead.
+func (*ListDiskImageRequest) Descriptor() ([]byte, []int) {
+	return file_civo_v1_diskimage_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListDiskImageRequest) GetDistribution() string {
+	if x != nil {
+		return x.Distribution
+	}
+	return ""
+}
+
+type DiskImage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Name to use as the image of a virtual machine, such as ubuntu-jammy
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Distribution, version and release name, such as ubuntu, 22.04 and jammy
+	Distribution string `protobuf:"bytes,3,opt,name=distribution,proto3" json:"distribution,omitempty"`
+	Version      string `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Label        string `protobuf:"bytes,5,opt,name=label,proto3" json:"label,omitempty"`
+	// available when virtual machines can be created from the image
+	State string `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	// Other image values resolving to this image, such as ubuntu and ubuntu:22.04
+	Aliases []string `protobuf:"bytes,7,rep,name=aliases,proto3" json:"aliases,omitempty"`
+}
+
+func (x *DiskImage) Reset() {
+	*x = DiskImage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_civo_v1_diskimage_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiskImage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskImage) ProtoMessage() {}
+
+func (x *DiskImage) ProtoReflect() protoreflect.Message {
+	mi := &file_civo_v1_diskimage_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskImage.ProtoReflect.Descriptor instead.
+func (*DiskImage) Descriptor() ([]byte, []int) {
+	return file_civo_v1_diskimage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DiskImage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DiskImage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DiskImage) GetDistribution() string {
+	if x != nil {
+		return x.Distribution
+	}
+	return ""
+}
+
+func (x *DiskImage) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DiskImage) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *DiskImage) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *DiskImage) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+type DiskImageList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*DiskImage `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *DiskImageList) Reset() {
+	*x = DiskImageList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_civo_v1_diskimage_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiskImageList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskImageList) ProtoMessage() {}
+
+func (x *DiskImageList) ProtoReflect() protoreflect.Message {
+	mi := &file_civo_v1_diskimage_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskImageList.ProtoReflect.Descriptor instead.
+func (*DiskImageList) Descriptor() ([]byte, []int) {
+	return file_civo_v1_diskimage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DiskImageList) GetItems() []*DiskImage {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+var File_civo_v1_diskimage_proto protoreflect.FileDescriptor
+
+var file_civo_v1_diskimage_proto_rawDesc = []byte{
+	0x0a, 0x17, 0x63, 0x69, 0x76, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x69, 0x73, 0x6b, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x63, 0x69, 0x76, 0x6f, 0x2e,
+	0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x76, 0x31, 0x22, 0x3a, 0x0a, 0x14, 0x4c, 0x69, 0x73,
+	0x74, 0x44, 0x69, 0x73, 0x6b, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62,
+	0x75, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xb3, 0x01, 0x0a, 0x09, 0x44, 0x69, 0x73, 0x6b, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64,
+	0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x65, 0x73, 0x22, 0x40, 0x0a, 0x0d, 0x44,
+	0x69, 0x73, 0x6b, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x05,
+	0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x63, 0x69,
+	0x76, 0x6f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73,
+	0x6b, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x32, 0x6a, 0x0a,
+	0x10, 0x44, 0x69, 0x73, 0x6b, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x56, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x69, 0x73, 0x6b, 0x49, 0x6d, 0x61,
+	0x67, 0x65, 0x12, 0x24, 0x2e, 0x63, 0x69, 0x76, 0x6f, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x69, 0x73, 0x6b, 0x49, 0x6d, 0x61, 0x67,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x63, 0x69, 0x76, 0x6f, 0x2e,
+	0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x6b, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x00, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x69, 0x76, 0x6f, 0x2f, 0x63, 0x69, 0x76,
+	0x6f, 0x2d, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x69, 0x76,
+	0x6f, 0x2f, 0x76, 0x31, 0x3b, 0x63, 0x69, 0x76, 0x6f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_civo_v1_diskimage_proto_rawDescOnce sync.Once
+	file_civo_v1_diskimage_proto_rawDescData = file_civo_v1_diskimage_proto_rawDesc
+)
+
+func file_civo_v1_diskimage_proto_rawDescGZIP() []byte {
+	file_civo_v1_diskimage_proto_rawDescOnce.Do(func() {
+		file_civo_v1_diskimage_proto_rawDescData = protoimpl.X.CompressGZIP(file_civo_v1_diskimage_proto_rawDescData)
+	})
+	return file_civo_v1_diskimage_proto_rawDescData
+}
+
+var file_civo_v1_diskimage_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_civo_v1_diskimage_proto_goTypes = []interface{}{
+	(*ListDiskImageRequest)(nil), // 0: civo.opencp.v1.ListDiskImageRequest
+	(*DiskImage)(nil),            // 1: civo.opencp.v1.DiskImage
+	(*DiskImageList)(nil),        // 2: civo.opencp.v1.DiskImageList
+}
+var file_civo_v1_diskimage_proto_depIdxs = []int32{
+	1, // 0: civo.opencp.v1.DiskImageList.items:type_name -> civo.opencp.v1.DiskImage
+	0, // 1: civo.opencp.v1.DiskImageService.ListDiskImage:input_type -> civo.opencp.v1.ListDiskImageRequest
+	2, // 2: civo.opencp.v1.DiskImageService.ListDiskImage:output_type -> civo.opencp.v1.DiskImageList
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_civo_v1_diskimage_proto_init() }
+func file_civo_v1_diskimage_proto_init() {
+	if File_civo_v1_diskimage_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_civo_v1_diskimage_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListDiskImageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_civo_v1_diskimage_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DiskImage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_civo_v1_diskimage_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DiskImageList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_civo_v1_diskimage_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_civo_v1_diskimage_proto_goTypes,
+		DependencyIndexes: file_civo_v1_diskimage_proto_depIdxs,
+		MessageInfos:      file_civo_v1_diskimage_proto_msgTypes,
+	}.Build()
+	File_civo_v1_diskimage_proto = out.File
+	file_civo_v1_diskimage_proto_rawDesc = nil
+	file_civo_v1_diskimage_proto_goTypes = nil
+	file_civo_v1_diskimage_proto_depIdxs = nil
+}
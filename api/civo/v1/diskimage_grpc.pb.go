@@ -0,0 +1,107 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: civo/v1/diskimage.proto
+
+package civov1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DiskImageServiceClient is the client API for DiskImageService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DiskImageServiceClient interface {
+	// ListDiskImage returns the disk images of the region, the newest versions first
+	ListDiskImage(ctx context.Context, in *ListDiskImageRequest, opts ...grpc.CallOption) (*DiskImageList, error)
+}
+
+type diskImageServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDiskImageServiceClient(cc grpc.ClientConnInterface) DiskImageServiceClient {
+	return &diskImageServiceClient{cc}
+}
+
+func (c *diskImageServiceClient) ListDiskImage(ctx context.Context, in *ListDiskImageRequest, opts ...grpc.CallOption) (*DiskImageList, error) {
+	out := new(DiskImageList)
+	err := c.cc.Invoke(ctx, "/civo.opencp.v1.DiskImageService/ListDiskImage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DiskImageServiceServer is the server API for DiskImageService service.
+// All implementations must embed UnimplementedDiskImageServiceServer
+// for forward compatibility
+type DiskImageServiceServer interface {
+	// ListDiskImage returns the disk images of the region, the newest versions first
+	ListDiskImage(context.Context, *ListDiskImageRequest) (*DiskImageList, error)
+	mustEmbedUnimplementedDiskImageServiceServer()
+}
+
+// UnimplementedDiskImageServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDiskImageServiceServer struct {
+}
+
+func (UnimplementedDiskImageServiceServer) ListDiskImage(context.Context, *ListDiskImageRequest) (*DiskImageList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDiskImage not implemented")
+}
+func (UnimplementedDiskImageServiceServer) mustEmbedUnimplementedDiskImageServiceServer() {}
+
+// UnsafeDiskImageServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DiskImageServiceServer will
+// result in compilation errors.
+type UnsafeDiskImageServiceServer interface {
+	mustEmbedUnimplementedDiskImageServiceServer()
+}
+
+func RegisterDiskImageServiceServer(s grpc.ServiceRegistrar, srv DiskImageServiceServer) {
+	s.RegisterService(&DiskImageService_ServiceDesc, srv)
+}
+
+func _DiskImageService_ListDiskImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDiskImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DiskImageServiceServer).ListDiskImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/civo.opencp.v1.DiskImageService/ListDiskImage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DiskImageServiceServer).ListDiskImage(ctx, req.(*ListDiskImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DiskImageService_ServiceDesc is the grpc.ServiceDesc for DiskImageService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DiskImageService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "civo.opencp.v1.DiskImageService",
+	HandlerType: (*DiskImageServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListDiskImage",
+			Handler:    _DiskImageService_ListDiskImage_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "civo/v1/diskimage.proto",
+}
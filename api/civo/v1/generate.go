@@ -0,0 +1,7 @@
+// Package civov1 holds the Civo specific gRPC services, which are not part of the OpenCP specification
+package civov1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative civo/v1/info.proto civo/v1/diskimage.proto
+
+// power.proto imports the messages of the OpenCP specification from the opencp-spec module
+//go:generate sh -c "protoc -I ../.. -I $(go list -m -f '{{.Dir}}' github.com/opencontrolplane/opencp-spec)/grpc --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative civo/v1/power.proto"
@@ -0,0 +1,97 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.21.12
+// source: civo/v1/power.proto
+
+package civov1
+
+import (
+	grpc "github.com/opencontrolplane/opencp-spec/grpc"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_civo_v1_power_proto protoreflect.FileDescriptor
+
+var file_civo_v1_power_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x63, 0x69, 0x76, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x6f, 0x77, 0x65, 0x72, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x63, 0x69, 0x76, 0x6f, 0x2e, 0x6f, 0x70, 0x65, 0x6e,
+	0x63, 0x70, 0x2e, 0x76, 0x31, 0x1a, 0x0c, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x32, 0xb7, 0x02, 0x0a, 0x0c, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x46, 0x0a, 0x13, 0x53, 0x74, 0x61, 0x72, 0x74, 0x56, 0x69, 0x72,
+	0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x12, 0x15, 0x2e, 0x6f, 0x70,
+	0x65, 0x6e, 0x63, 0x70, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x1a, 0x16, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x56, 0x69, 0x72, 0x74,
+	0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x12,
+	0x53, 0x74, 0x6f, 0x70, 0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69,
+	0x6e, 0x65, 0x12, 0x15, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x16, 0x2e, 0x6f, 0x70, 0x65, 0x6e,
+	0x63, 0x70, 0x2e, 0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e,
+	0x65, 0x22, 0x00, 0x12, 0x4b, 0x0a, 0x18, 0x53, 0x6f, 0x66, 0x74, 0x52, 0x65, 0x62, 0x6f, 0x6f,
+	0x74, 0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x12,
+	0x15, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x16, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e,
+	0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x22, 0x00,
+	0x12, 0x4b, 0x0a, 0x18, 0x48, 0x61, 0x72, 0x64, 0x52, 0x65, 0x62, 0x6f, 0x6f, 0x74, 0x56, 0x69,
+	0x72, 0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x12, 0x15, 0x2e, 0x6f,
+	0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x4f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x1a, 0x16, 0x2e, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2e, 0x56, 0x69, 0x72,
+	0x74, 0x75, 0x61, 0x6c, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x22, 0x00, 0x42, 0x30, 0x5a,
+	0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x69, 0x76, 0x6f,
+	0x2f, 0x63, 0x69, 0x76, 0x6f, 0x2d, 0x6f, 0x70, 0x65, 0x6e, 0x63, 0x70, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x63, 0x69, 0x76, 0x6f, 0x2f, 0x76, 0x31, 0x3b, 0x63, 0x69, 0x76, 0x6f, 0x76, 0x31, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var file_civo_v1_power_proto_goTypes = []interface{}{
+	(*grpc.FilterOptions)(nil),  // 0: opencp.FilterOptions
+	(*grpc.VirtualMachine)(nil), // 1: opencp.VirtualMachine
+}
+var file_civo_v1_power_proto_depIdxs = []int32{
+	0, // 0: civo.opencp.v1.PowerService.StartVirtualMachine:input_type -> opencp.FilterOptions
+	0, // 1: civo.opencp.v1.PowerService.StopVirtualMachine:input_type -> opencp.FilterOptions
+	0, // 2: civo.opencp.v1.PowerService.SoftRebootVirtualMachine:input_type -> opencp.FilterOptions
+	0, // 3: civo.opencp.v1.PowerService.HardRebootVirtualMachine:input_type -> opencp.FilterOptions
+	1, // 4: civo.opencp.v1.PowerService.StartVirtualMachine:output_type -> opencp.VirtualMachine
+	1, // 5: civo.opencp.v1.PowerService.StopVirtualMachine:output_type -> opencp.VirtualMachine
+	1, // 6: civo.opencp.v1.PowerService.SoftRebootVirtualMachine:output_type -> opencp.VirtualMachine
+	1, // 7: civo.opencp.v1.PowerService.HardRebootVirtualMachine:output_type -> opencp.VirtualMachine
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_civo_v1_power_proto_init() }
+func file_civo_v1_power_proto_init() {
+	if File_civo_v1_power_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_civo_v1_power_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_civo_v1_power_proto_goTypes,
+		DependencyIndexes: file_civo_v1_power_proto_depIdxs,
+	}.Build()
+	File_civo_v1_power_proto = out.File
+	file_civo_v1_power_proto_rawDesc = nil
+	file_civo_v1_power_proto_goTypes = nil
+	file_civo_v1_power_proto_depIdxs = nil
+}
@@ -0,0 +1,222 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: civo/v1/power.proto
+
+package civov1
+
+import (
+	context "context"
+	grpc1 "github.com/opencontrolplane/opencp-spec/grpc"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PowerServiceClient is the client API for PowerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PowerServiceClient interface {
+	// StartVirtualMachine starts a stopped virtual machine
+	StartVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error)
+	// StopVirtualMachine shuts a virtual machine down, it is still billed while stopped
+	StopVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error)
+	// SoftRebootVirtualMachine asks the operating system of a virtual machine to reboot
+	SoftRebootVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error)
+	// HardRebootVirtualMachine power cycles a virtual machine
+	HardRebootVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error)
+}
+
+type powerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPowerServiceClient(cc grpc.ClientConnInterface) PowerServiceClient {
+	return &powerServiceClient{cc}
+}
+
+func (c *powerServiceClient) StartVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error) {
+	out := new(grpc1.VirtualMachine)
+	err := c.cc.Invoke(ctx, "/civo.opencp.v1.PowerService/StartVirtualMachine", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) StopVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error) {
+	out := new(grpc1.VirtualMachine)
+	err := c.cc.Invoke(ctx, "/civo.opencp.v1.PowerService/StopVirtualMachine", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) SoftRebootVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error) {
+	out := new(grpc1.VirtualMachine)
+	err := c.cc.Invoke(ctx, "/civo.opencp.v1.PowerService/SoftRebootVirtualMachine", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *powerServiceClient) HardRebootVirtualMachine(ctx context.Context, in *grpc1.FilterOptions, opts ...grpc.CallOption) (*grpc1.VirtualMachine, error) {
+	out := new(grpc1.VirtualMachine)
+	err := c.cc.Invoke(ctx, "/civo.opencp.v1.PowerService/HardRebootVirtualMachine", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PowerServiceServer is the server API for PowerService service.
+// All implementations must embed UnimplementedPowerServiceServer
+// for forward compatibility
+type PowerServiceServer interface {
+	// StartVirtualMachine starts a stopped virtual machine
+	StartVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error)
+	// StopVirtualMachine shuts a virtual machine down, it is still billed while stopped
+	StopVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error)
+	// SoftRebootVirtualMachine asks the operating system of a virtual machine to reboot
+	SoftRebootVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error)
+	// HardRebootVirtualMachine power cycles a virtual machine
+	HardRebootVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error)
+	mustEmbedUnimplementedPowerServiceServer()
+}
+
+// UnimplementedPowerServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPowerServiceServer struct {
+}
+
+func (UnimplementedPowerServiceServer) StartVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartVirtualMachine not implemented")
+}
+func (UnimplementedPowerServiceServer) StopVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopVirtualMachine not implemented")
+}
+func (UnimplementedPowerServiceServer) SoftRebootVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SoftRebootVirtualMachine not implemented")
+}
+func (UnimplementedPowerServiceServer) HardRebootVirtualMachine(context.Context, *grpc1.FilterOptions) (*grpc1.VirtualMachine, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HardRebootVirtualMachine not implemented")
+}
+func (UnimplementedPowerServiceServer) mustEmbedUnimplementedPowerServiceServer() {}
+
+// UnsafePowerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PowerServiceServer will
+// result in compilation errors.
+type UnsafePowerServiceServer interface {
+	mustEmbedUnimplementedPowerServiceServer()
+}
+
+func RegisterPowerServiceServer(s grpc.ServiceRegistrar, srv PowerServiceServer) {
+	s.RegisterService(&PowerService_ServiceDesc, srv)
+}
+
+func _PowerService_StartVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(grpc1.FilterOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).StartVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/civo.opencp.v1.PowerService/StartVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).StartVirtualMachine(ctx, req.(*grpc1.FilterOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_StopVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(grpc1.FilterOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).StopVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/civo.opencp.v1.PowerService/StopVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).StopVirtualMachine(ctx, req.(*grpc1.FilterOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_SoftRebootVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(grpc1.FilterOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).SoftRebootVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/civo.opencp.v1.PowerService/SoftRebootVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).SoftRebootVirtualMachine(ctx, req.(*grpc1.FilterOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PowerService_HardRebootVirtualMachine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(grpc1.FilterOptions)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PowerServiceServer).HardRebootVirtualMachine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/civo.opencp.v1.PowerService/HardRebootVirtualMachine",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PowerServiceServer).HardRebootVirtualMachine(ctx, req.(*grpc1.FilterOptions))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PowerService_ServiceDesc is the grpc.ServiceDesc for PowerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PowerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "civo.opencp.v1.PowerService",
+	HandlerType: (*PowerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartVirtualMachine",
+			Handler:    _PowerService_StartVirtualMachine_Handler,
+		},
+		{
+			MethodName: "StopVirtualMachine",
+			Handler:    _PowerService_StopVirtualMachine_Handler,
+		},
+		{
+			MethodName: "SoftRebootVirtualMachine",
+			Handler:    _PowerService_SoftRebootVirtualMachine_Handler,
+		},
+		{
+			MethodName: "HardRebootVirtualMachine",
+			Handler:    _PowerService_HardRebootVirtualMachine_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "civo/v1/power.proto",
+}
@@ -1,25 +1,40 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"flag"
 	"log"
-	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/civo/civo-opencp/pkg"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
-	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
 )
 
 func main() {
-	logrus.SetLevel(logrus.InfoLevel)
+	config, err := pkg.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("failed to load the config: %v", err)
+	}
+
+	level, _ := logrus.ParseLevel(config.LogLevel)
+	logrus.SetLevel(level)
 	logrus.SetOutput(os.Stdout)
-	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetFormatter(config.Formatter())
+	logrus.AddHook(pkg.RedactHook{})
 	logger := logrus.WithFields(logrus.Fields{})
 
 	opts := []grpc_logrus.Option{
@@ -30,35 +45,190 @@ func main() {
 
 	grpc_logrus.ReplaceGrpcLogger(logger)
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", 8080))
+	var newProvider pkg.ProviderFactory
+	switch config.Backend {
+	case "civo":
+		newProvider = pkg.NewCivoProviderFactory(config.CivoAPIURL)
+	case "memory":
+		newProvider = pkg.NewMemoryBackend().Provider
+		logger.Warn("using the in-memory backend, no resources will be created in Civo")
+	}
+	clientCache := pkg.NewClientCache(newProvider, 1024, 10*time.Minute)
+	authFunc := pkg.AuthMiddleware(clientCache.Provider, config.Region)
+
+	lis, err := config.Listener()
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer(
+	serverOpts := []grpc.ServerOption{}
+	if config.TLS.Enabled() {
+		tlsReloader, err := pkg.NewTLSReloader(config.TLS)
+		if err != nil {
+			log.Fatalf("failed to load the TLS certificates: %v", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(tlsReloader.Credentials()))
+	} else {
+		logger.Warn("TLS is off, the Civo API tokens are sent in plaintext")
+	}
+
+	shutdownTracing, err := pkg.SetupTracing(context.Background(), config.Tracing)
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+
+	auditor, err := pkg.NewAuditor(config.Audit)
+	if err != nil {
+		log.Fatalf("failed to set up the audit log: %v", err)
+	}
+	defer auditor.Close()
+
+	// Each retry of a Civo API call is traced, measured and logged on its own
+	civoAPIHooks := []pkg.ProviderCallHook{
+		pkg.CivoAPIRetries(config.Retries),
+		pkg.CivoAPITracing(),
+		pkg.CivoAPIMetrics(),
+		pkg.CivoAPILogging(),
+	}
+
+	readOnly := pkg.NewReadOnly(config.ReadOnly, config.ReadOnlyTokens)
+	authorizer, err := pkg.NewAuthorizer(config.PolicyFile)
+	if err != nil {
+		log.Fatalf("failed to load the authorization policy: %v", err)
+	}
+	revealPolicy := pkg.RevealPolicy(pkg.DenyReveal)
+	if config.AllowRevealSecrets {
+		revealPolicy = authorizer.RevealSecrets
+	}
+	rateLimiter := pkg.NewRateLimiter(config.RateLimits)
+	idempotencyCache := pkg.NewIdempotencyCache(config.Idempotency)
+	tracker := pkg.NewCallTracker()
+	grpcServer := grpc.NewServer(append(serverOpts,
+		grpc.ConnectionTimeout(config.Timeouts.Connection.Duration),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: config.Timeouts.Idle.Duration,
+		}),
 		grpc_middleware.WithStreamServerChain(
-			grpc_auth.StreamServerInterceptor(pkg.AuthMiddlewareFunc),
+			pkg.TracingStreamServerInterceptor(),
+			pkg.RequestIDStreamServerInterceptor(logger),
+			tracker.StreamServerInterceptor(),
+			pkg.MetricsStreamServerInterceptor(),
+			readOnly.StreamServerInterceptor(),
+			grpc_auth.StreamServerInterceptor(authFunc),
+			grpc_logrus.StreamServerInterceptor(logger, opts...),
+			authorizer.StreamServerInterceptor(),
+			rateLimiter.StreamServerInterceptor(),
+			pkg.ProviderStreamServerInterceptor(civoAPIHooks...),
+			pkg.ErrorStreamServerInterceptor(),
 		),
 		grpc_middleware.WithUnaryServerChain(
-			grpc_auth.UnaryServerInterceptor(pkg.AuthMiddlewareFunc),
+			pkg.TracingUnaryServerInterceptor(),
+			pkg.RequestIDUnaryServerInterceptor(logger),
+			tracker.UnaryServerInterceptor(),
+			pkg.MetricsUnaryServerInterceptor(),
+			readOnly.UnaryServerInterceptor(),
+			grpc_auth.UnaryServerInterceptor(authFunc),
 			grpc_logrus.UnaryServerInterceptor(logger, opts...),
+			pkg.TimeoutUnaryServerInterceptor(config.Timeouts.Request.Duration),
+			auditor.UnaryServerInterceptor(),
+			authorizer.UnaryServerInterceptor(),
+			rateLimiter.UnaryServerInterceptor(),
+			pkg.DryRunUnaryServerInterceptor(),
+			pkg.SecretsUnaryServerInterceptor(revealPolicy, auditor.Reveal),
+			idempotencyCache.UnaryServerInterceptor(),
+			pkg.RegionUnaryServerInterceptor(),
+			pkg.ProviderUnaryServerInterceptor(civoAPIHooks...),
+			pkg.ErrorUnaryServerInterceptor(),
 		),
-	)
-
-	opencpspec.RegisterLoginServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterVirtualMachineServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterKubernetesClusterServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterNamespaceServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterDomainServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterSSHKeyServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterFirewallServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterIpServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterDatabaseServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterObjectStorageServiceServer(grpcServer, &pkg.Server{})
-	opencpspec.RegisterObjectStorageCredentialServiceServer(grpcServer, &pkg.Server{})
-
-	log.Printf("server listening at %v", lis.Addr())
-	if err := grpcServer.Serve(lis); err != nil {
+	)...)
+
+	services, err := pkg.RegisterServices(grpcServer, &pkg.Server{}, config.Services)
+	if err != nil {
+		log.Fatalf("failed to register the services: %v", err)
+	}
+
+	pkg.NewInfoServer(services, config.Region, readOnly).Register(grpcServer)
+	pkg.RegisterReflection(grpcServer)
+
+	// The memory backend has no Civo API to probe
+	probeURL := config.CivoAPIURL
+	if config.Backend == "memory" {
+		probeURL = ""
+	}
+	serverHealth := pkg.NewHealth(services, probeURL, config.Health.ProbeTimeout.Duration, readOnly.ServerMode())
+	serverHealth.Register(grpcServer)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	go serverHealth.Run(ctx, config.Health.ProbeInterval.Duration)
+
+	var httpServer *http.Server
+	if config.HTTPListen != "" {
+		mux := http.NewServeMux()
+		serverHealth.RegisterHTTP(mux)
+		mux.Handle("/metrics", promhttp.Handler())
+		httpServer = &http.Server{Addr: config.HTTPListen, Handler: mux}
+
+		go func() {
+			logger.Infof("HTTP endpoints listening at %v", config.HTTPListen)
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("failed to serve the HTTP endpoints: %v", err)
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.WithField("services", services).Infof("server listening at %v", lis.Addr())
+		serveErr <- grpcServer.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("failed to serve: %v", err)
+	case <-ctx.Done():
+		stop()
+	}
+
+	// Tell the probes and clients first, then wait for the running calls
+	logger.Infof("shutting down, waiting up to %s for the running calls", config.Timeouts.ShutdownGrace.Duration)
+	serverHealth.Shutdown()
+	gracefulStop(grpcServer, config.Timeouts.ShutdownGrace.Duration, tracker, logger)
+
+	if httpServer != nil {
+		httpServer.Close()
+	}
+
+	// Export the spans of the last calls
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.WithError(err).Warn("failed to flush the spans")
+	}
+	cancel()
+	logger.Info("server stopped")
+}
+
+// gracefulStop stops the server once the running calls are done, and cuts them off after the grace period
+func gracefulStop(server *grpc.Server, grace time.Duration, tracker *pkg.CallTracker, logger *logrus.Entry) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return
+	case <-time.After(grace):
+	}
+
+	for _, call := range tracker.Running() {
+		logger.WithFields(logrus.Fields{
+			"grpc.method":     call.Method,
+			"grpc.start_time": call.Started.Format(time.RFC3339),
+			"grpc.time_ns":    time.Since(call.Started).Nanoseconds(),
+		}).Warn("call cut off by the shutdown")
 	}
+	server.Stop()
 }
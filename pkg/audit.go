@@ -0,0 +1,431 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuditConfig is where the audit records are written
+type AuditConfig struct {
+	// Sinks are the names of the sinks, see AuditSinkNames
+	Sinks []string `yaml:"sinks"`
+
+	// File is the JSONL file of the file sink. It is rotated once it reaches MaxSizeMB,
+	// keeping MaxBackups old files
+	File       string `yaml:"file"`
+	MaxSizeMB  int    `yaml:"maxSizeMB"`
+	MaxBackups int    `yaml:"maxBackups"`
+}
+
+// validate returns the problems of the audit settings
+func (c *AuditConfig) validate() []string {
+	problems := []string{}
+
+	for _, name := range c.Sinks {
+		if _, ok := auditSinks[name]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown audit sink %q, must be one of %s", name, strings.Join(AuditSinkNames(), ", ")))
+		}
+		if name == "file" && c.File == "" {
+			problems = append(problems, "the file audit sink needs a file")
+		}
+	}
+
+	if c.MaxSizeMB < 1 || c.MaxBackups < 0 {
+		problems = append(problems, "the audit file max size must be positive and the max backups can't be negative")
+	}
+
+	return problems
+}
+
+// AuditSink writes the audit records somewhere
+type AuditSink interface {
+	Write(record *AuditRecord) error
+	Close() error
+}
+
+// auditSinks create the sinks by the name used in the config
+var auditSinks = map[string]func(c AuditConfig) (AuditSink, error){
+	"stdout": func(c AuditConfig) (AuditSink, error) {
+		return &jsonlSink{w: os.Stdout}, nil
+	},
+	"file": func(c AuditConfig) (AuditSink, error) {
+		return newRotatingFileSink(c.File, int64(c.MaxSizeMB)<<20, c.MaxBackups)
+	},
+}
+
+// AuditSinkNames returns the names of the audit sinks, sorted
+func AuditSinkNames() []string {
+	names := make([]string, 0, len(auditSinks))
+	for name := range auditSinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// AuditRecord is what the audit log keeps of a call
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	RequestID string    `json:"request_id,omitempty"`
+
+	// The caller
+	AccountID     string `json:"account_id,omitempty"`
+	TokenHash     string `json:"token_hash,omitempty"`
+	ClientSubject string `json:"client_subject,omitempty"`
+
+	Method string      `json:"method"`
+	Target AuditTarget `json:"target"`
+	DryRun bool        `json:"dry_run,omitempty"`
+
+	// Request is the body of the call without its secrets
+	Request interface{} `json:"request,omitempty"`
+
+	// RevealedSecrets are the secrets shown in clear to the caller
+	RevealedSecrets []string `json:"revealed_secrets,omitempty"`
+
+	Outcome         string  `json:"outcome"`
+	Code            string  `json:"code"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// AuditTarget is the resource a call is about
+type AuditTarget struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// Auditor records the calls changing resources, and the reveals of secrets, into its sinks
+type Auditor struct {
+	sinks []AuditSink
+}
+
+// NewAuditor creates the sinks of the config
+func NewAuditor(c AuditConfig) (*Auditor, error) {
+	a := &Auditor{}
+	for _, name := range c.Sinks {
+		sink, err := auditSinks[name](c)
+		if err != nil {
+			a.Close()
+			return nil, fmt.Errorf("creating the %s audit sink: %w", name, err)
+		}
+		a.sinks = append(a.sinks, sink)
+	}
+
+	return a, nil
+}
+
+// UnaryServerInterceptor records the calls changing resources
+func (a *Auditor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_, method := splitMethod(info.FullMethod)
+		if len(a.sinks) == 0 || !isMutatingMethod(method) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		record := newAuditRecord(ctx, "call", info.FullMethod)
+		record.Target = auditTarget(req, resp)
+		record.Request = redactedBody(req)
+		record.DurationSeconds = time.Since(start).Seconds()
+
+		s := status.Convert(CivoErrorToStatus(err))
+		record.Code = s.Code().String()
+		record.Outcome = "success"
+		if err != nil {
+			record.Outcome = "failure"
+			record.Error = s.Message()
+		}
+
+		a.write(ctx, record)
+
+		return resp, err
+	}
+}
+
+// Reveal is the RevealAudit recording the secrets shown in clear to the caller
+func (a *Auditor) Reveal(ctx context.Context, fullMethod string, secrets []string) {
+	record := newAuditRecord(ctx, "reveal_secrets", fullMethod)
+	record.RevealedSecrets = secrets
+	record.Outcome = "success"
+	record.Code = "OK"
+
+	a.write(ctx, record)
+}
+
+// Close closes the sinks
+func (a *Auditor) Close() error {
+	var firstErr error
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// write writes the record into every sink, a failing sink does not fail the call
+func (a *Auditor) write(ctx context.Context, record *AuditRecord) {
+	for _, sink := range a.sinks {
+		if err := sink.Write(record); err != nil {
+			Logger(ctx).WithError(err).WithField("grpc.method", record.Method).Error("failed to write the audit record")
+		}
+	}
+}
+
+// isMutatingMethod returns true if the method changes resources
+func isMutatingMethod(method string) bool {
+	for _, prefix := range []string{"Create", "Delete", "Update", "Start", "Stop", "SoftReboot", "HardReboot"} {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newAuditRecord creates the record of a call with the identity of its caller
+func newAuditRecord(ctx context.Context, event, fullMethod string) *AuditRecord {
+	record := &AuditRecord{
+		Time:          time.Now().UTC(),
+		Event:         event,
+		TokenHash:     CallerTokenHash(ctx),
+		ClientSubject: ClientSubject(ctx),
+		Method:        fullMethod,
+		DryRun:        isDryRun(ctx),
+	}
+
+	if id, ok := ctx.Value("requestID").(string); ok {
+		record.RequestID = id
+	}
+	if client, ok := ctx.Value("client").(Provider); ok {
+		record.AccountID = client.GetAccountID()
+	}
+
+	return record
+}
+
+// auditTarget returns the resource of a call, from the resource or the filter sent,
+// completed with the resource answered
+func auditTarget(req, resp interface{}) AuditTarget {
+	target := AuditTarget{}
+
+	if filter, ok := req.(*opencpspec.FilterOptions); ok && filter != nil {
+		if filter.Name != nil {
+			target.Name = *filter.Name
+		}
+		if filter.Namespace != nil {
+			target.Namespace = *filter.Namespace
+		}
+		if filter.Id != nil {
+			target.UID = *filter.Id
+		}
+	}
+
+	for _, msg := range []interface{}{req, resp} {
+		meta := objectMeta(msg)
+		if meta == nil {
+			continue
+		}
+		if target.Name == "" {
+			target.Name = meta.Name
+		}
+		if target.Namespace == "" {
+			target.Namespace = meta.Namespace
+		}
+		if target.UID == "" {
+			target.UID = string(meta.UID)
+		}
+	}
+
+	return target
+}
+
+// objectMeta returns the Metadata of an OpenCP resource, or nil
+func objectMeta(msg interface{}) *metav1.ObjectMeta {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	field := v.Elem().FieldByName("Metadata")
+	if !field.IsValid() {
+		return nil
+	}
+
+	meta, _ := field.Interface().(*metav1.ObjectMeta)
+	return meta
+}
+
+// redactedBody returns the body of a call as JSON values, without the fields named after a secret
+func redactedBody(req interface{}) interface{} {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil
+	}
+
+	return redactField("request", body)
+}
+
+// jsonlSink writes one JSON record per line
+type jsonlSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Write writes the record on its own line
+func (s *jsonlSink) Write(record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close does nothing, the writer is not the sink's
+func (s *jsonlSink) Close() error {
+	return nil
+}
+
+// auditBackupTimeFormat is the time in the names of the rotated audit files
+const auditBackupTimeFormat = "20060102T150405.000"
+
+// rotatingFileSink writes one JSON record per line into a file, renamed with the time
+// once it reaches maxSize, keeping maxBackups of the renamed files
+type rotatingFileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// newRotatingFileSink opens the file, appending to it
+func newRotatingFileSink(path string, maxSize int64, maxBackups int) (*rotatingFileSink, error) {
+	s := &rotatingFileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// open opens the file and reads its size
+func (s *rotatingFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// Write writes the record on its own line, rotating the file first when it would get too big
+func (s *rotatingFileSink) Write(record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			logrus.WithError(err).WithField("file", s.path).Error("failed to rotate the audit file, still writing to the current one")
+		}
+	}
+
+	n, err := s.file.Write(data)
+	s.size += int64(n)
+	return err
+}
+
+// rotate renames the file with the time, opens a new one and removes the oldest backups. When the
+// rename or the open fails the current file is kept, so the records are still written somewhere.
+// It must be called with the lock held
+func (s *rotatingFileSink) rotate() error {
+	ext := filepath.Ext(s.path)
+	prefix := strings.TrimSuffix(s.path, ext) + "-"
+	backup := prefix + time.Now().UTC().Format(auditBackupTimeFormat) + ext
+	if err := os.Rename(s.path, backup); err != nil {
+		return err
+	}
+
+	current := s.file
+	if err := s.open(); err != nil {
+		return err
+	}
+	current.Close()
+
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return err
+	}
+
+	// Only the files named like the backups of the sink are removed
+	backups := []string{}
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if _, err := time.Parse(auditBackupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+	for len(backups) > s.maxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// Close closes the file
+func (s *rotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
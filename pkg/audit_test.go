@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSink(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+
+	// A file named like the audit file but not one of its backups must be left alone
+	notes := filepath.Join(dir, "audit-notes.jsonl")
+	if err := os.WriteFile(notes, []byte("keep me\n"), 0o600); err != nil {
+		t.Fatalf("writing the unrelated file: %v", err)
+	}
+
+	sink, err := newRotatingFileSink(path, 1, 2)
+	if err != nil {
+		t.Fatalf("opening the audit file: %v", err)
+	}
+	defer sink.Close()
+
+	// Every record after the first rotates the file, as it is over the max size
+	methods := []string{"CreateDomain", "CreateFirewall", "CreateNamespace", "CreateSSHKey", "CreateVirtualMachine"}
+	for _, method := range methods {
+		if err := sink.Write(&AuditRecord{Event: "call", Method: method}); err != nil {
+			t.Fatalf("writing the record of %s: %v", method, err)
+		}
+		// The backups are named after the time, to the millisecond
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatalf("listing the audit files: %v", err)
+	}
+	sort.Strings(files)
+	if len(files) != 4 || files[2] != notes || files[3] != path {
+		t.Fatalf("got the files %v, want the audit file, 2 backups and the unrelated file", files)
+	}
+
+	wants := map[string]string{files[0]: "CreateNamespace", files[1]: "CreateSSHKey", path: "CreateVirtualMachine", notes: "keep me"}
+	for file, want := range wants {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if !strings.Contains(string(data), want) || strings.Count(string(data), "\n") != 1 {
+			t.Errorf("%s has %q, want the one line with %s", filepath.Base(file), data, want)
+		}
+	}
+}
@@ -2,18 +2,25 @@ package pkg
 
 import (
 	"context"
-	"log"
-	"os"
+	"strings"
+	"time"
 
-	"github.com/civo/civogo"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var Version = "1.0.0"
 
 func (s *Server) Check(ctx context.Context, in *opencpspec.LoginRequest) (*opencpspec.LoginResponse, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	// The token was refused by the auth middleware
+	client, ok := ctx.Value("client").(Provider)
+	if !ok {
+		return &opencpspec.LoginResponse{Valid: false}, nil
+	}
+
 	result := client.GetAccountID()
 	if result == "" {
 		return &opencpspec.LoginResponse{Valid: false}, nil
@@ -22,27 +29,49 @@ func (s *Server) Check(ctx context.Context, in *opencpspec.LoginRequest) (*openc
 	return &opencpspec.LoginResponse{Valid: true}, nil
 }
 
-// exampleAuthFunc is used by a middleware to authenticate requests
-func AuthMiddlewareFunc(ctx context.Context) (context.Context, error) {
-	token, err := grpc_auth.AuthFromMD(ctx, "bearer")
-	if err != nil {
-		return nil, err
-	}
+// AuthMiddleware returns the function used by a middleware to authenticate requests,
+// the client for the token is created with the given provider factory in the region
+// asked for in the RegionMetadataKey metadata, or in defaultRegion
+func AuthMiddleware(newProvider ProviderFactory, defaultRegion string) grpc_auth.AuthFunc {
+	regions := newRegionCache(time.Hour)
 
-	if token != "" {
-		regionCode := os.Getenv("REGION")
-		userAgent := &civogo.Component{
-			Name:    "opencp.io",
-			Version: Version,
-		}
-		client, err := civogo.NewClient(token, regionCode)
+	return func(ctx context.Context) (context.Context, error) {
+		token, err := grpc_auth.AuthFromMD(ctx, "bearer")
 		if err != nil {
-			log.Println(err)
-			return ctx, err
+			return nil, err
 		}
-		client.SetUserAgent(userAgent)
-		ctx = context.WithValue(ctx, "client", client)
+
+		if token != "" {
+			client, err := newProvider(token, defaultRegion)
+			if err != nil {
+				// Login/Check tells the caller if the token is valid, so it must not fail on a bad token
+				if status.Code(err) == codes.Unauthenticated && isLoginCheck(ctx) {
+					return ctx, nil
+				}
+				Logger(ctx).WithError(err).Warn("failed to create the Civo client")
+				return ctx, err
+			}
+
+			// Use the regions of the metadata, checking the account has them
+			if requested := requestedRegions(ctx); len(requested) > 0 {
+				clients, err := regions.providers(token, client, requested, newProvider)
+				if err != nil {
+					Logger(ctx).WithError(err).Warn("failed to create the Civo clients of the regions")
+					return ctx, CivoErrorToStatus(err)
+				}
+				client = clients[0]
+				ctx = context.WithValue(ctx, "regions", clients)
+			}
+
+			ctx = context.WithValue(ctx, "client", client)
+		}
+
+		return ctx, nil
 	}
+}
 
-	return ctx, nil
+// isLoginCheck returns true if the call is the Login/Check method
+func isLoginCheck(ctx context.Context) bool {
+	method, _ := grpc.Method(ctx)
+	return strings.HasSuffix(method, "Login/Check")
 }
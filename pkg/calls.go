@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// CallTracker keeps the calls the server is running, so the ones cut off by a shutdown can be logged
+type CallTracker struct {
+	mu      sync.Mutex
+	next    uint64
+	running map[uint64]RunningCall
+}
+
+// RunningCall is a call the server has not answered yet
+type RunningCall struct {
+	Method  string
+	Started time.Time
+}
+
+// NewCallTracker creates an empty CallTracker
+func NewCallTracker() *CallTracker {
+	return &CallTracker{
+		running: map[uint64]RunningCall{},
+	}
+}
+
+// UnaryServerInterceptor tracks the unary calls
+func (t *CallTracker) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		defer t.start(info.FullMethod)()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor tracks the stream calls
+func (t *CallTracker) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer t.start(info.FullMethod)()
+		return handler(srv, stream)
+	}
+}
+
+// start adds a call and returns the function removing it
+func (t *CallTracker) start(method string) func() {
+	t.mu.Lock()
+	id := t.next
+	t.next++
+	t.running[id] = RunningCall{Method: method, Started: time.Now()}
+	t.mu.Unlock()
+
+	return func() {
+		t.mu.Lock()
+		delete(t.running, id)
+		t.mu.Unlock()
+	}
+}
+
+// Running returns the calls running now, the oldest first
+func (t *CallTracker) Running() []RunningCall {
+	t.mu.Lock()
+	calls := make([]RunningCall, 0, len(t.running))
+	for _, call := range t.running {
+		calls = append(calls, call)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].Started.Before(calls[j].Started)
+	})
+
+	return calls
+}
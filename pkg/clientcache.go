@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	clientCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "opencp_client_cache_hits_total",
+		Help: "Number of calls served with a cached token validation.",
+	})
+	clientCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "opencp_client_cache_misses_total",
+		Help: "Number of calls that had to validate their token against the Civo API.",
+	})
+	clientCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "opencp_client_cache_evictions_total",
+		Help: "Number of token validations removed from the cache because it was full or they expired.",
+	})
+	clientCacheRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "opencp_client_cache_rejected_tokens_total",
+		Help: "Number of tokens rejected by the Civo API when validating them.",
+	})
+	clientCacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "opencp_client_cache_entries",
+		Help: "Number of token validations in the cache.",
+	})
+)
+
+// ClientCache keeps the validation of each token and region so a token is not checked on every call.
+// A token is validated by listing its accounts the first time it is seen, and again once its entry expires.
+// Only the account ID is kept: every call still gets its own provider, as a civogo v0.3.24 client sets
+// its transport and its last response on every request and cannot be shared by concurrent calls.
+// For the same reason the providers do not share a pooled HTTP transport
+type ClientCache struct {
+	newProvider ProviderFactory
+	size        int
+	ttl         time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	pending map[string]*clientCacheCall
+}
+
+// clientCacheEntry is a validated token in the cache
+type clientCacheEntry struct {
+	key       string
+	accountID string
+	expires   time.Time
+}
+
+// clientCacheCall is a validation in progress, so concurrent calls with a new token only validate it once
+type clientCacheCall struct {
+	done      chan struct{}
+	accountID string
+	err       error
+}
+
+// cachedProvider is a provider whose account ID was already fetched when validating the token
+type cachedProvider struct {
+	Provider
+	accountID string
+}
+
+// GetAccountID returns the account ID found when the token was validated
+func (p *cachedProvider) GetAccountID() string {
+	return p.accountID
+}
+
+// NewClientCache creates a cache of at most size validations, kept for ttl, of the providers created with newProvider
+func NewClientCache(newProvider ProviderFactory, size int, ttl time.Duration) *ClientCache {
+	return &ClientCache{
+		newProvider: newProvider,
+		size:        size,
+		ttl:         ttl,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		pending:     map[string]*clientCacheCall{},
+	}
+}
+
+// Provider returns a new provider for the token and region, validating the token if it is not cached.
+// It can be used as a ProviderFactory
+func (c *ClientCache) Provider(token, region string) (Provider, error) {
+	accountID, err := c.accountID(token, region)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := c.newProvider(token, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedProvider{Provider: provider, accountID: accountID}, nil
+}
+
+// accountID returns the cached account ID of the token and region, validating the token if needed
+func (c *ClientCache) accountID(token, region string) (string, error) {
+	key := TokenHash(token) + "/" + region
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*clientCacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			clientCacheHits.Inc()
+			return entry.accountID, nil
+		}
+		c.remove(elem)
+	}
+
+	// Wait for the validation already running for this token
+	if call, ok := c.pending[key]; ok {
+		c.mu.Unlock()
+		<-call.done
+		return call.accountID, call.err
+	}
+
+	call := &clientCacheCall{done: make(chan struct{})}
+	c.pending[key] = call
+	c.mu.Unlock()
+
+	clientCacheMisses.Inc()
+	call.accountID, call.err = c.validate(token, region)
+
+	c.mu.Lock()
+	delete(c.pending, key)
+	if call.err == nil {
+		c.add(key, call.accountID)
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.accountID, call.err
+}
+
+// validate checks the token against the Civo API and returns its account ID
+func (c *ClientCache) validate(token, region string) (string, error) {
+	provider, err := c.newProvider(token, region)
+	if err != nil {
+		return "", err
+	}
+
+	// GetAccountID hides the errors of the Civo API, so an outage would look like a bad token
+	accounts, err := provider.ListAccounts()
+	if err != nil {
+		err = CivoErrorToStatus(err)
+		if code := status.Code(err); code == codes.Unauthenticated || code == codes.PermissionDenied {
+			clientCacheRejections.Inc()
+			return "", status.Error(codes.Unauthenticated, "the Civo API token is not valid")
+		}
+		return "", err
+	}
+	if len(accounts.Items) == 0 {
+		clientCacheRejections.Inc()
+		return "", status.Error(codes.Unauthenticated, "the Civo API token has no account")
+	}
+
+	return accounts.Items[0].ID, nil
+}
+
+// add puts a validation in the cache, evicting the least recently used ones when it is full.
+// The caller must hold the lock
+func (c *ClientCache) add(key, accountID string) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	for c.size > 0 && c.lru.Len() >= c.size {
+		c.remove(c.lru.Back())
+	}
+
+	c.entries[key] = c.lru.PushFront(&clientCacheEntry{
+		key:       key,
+		accountID: accountID,
+		expires:   time.Now().Add(c.ttl),
+	})
+	clientCacheEntries.Set(float64(c.lru.Len()))
+}
+
+// remove takes an entry out of the cache. The caller must hold the lock
+func (c *ClientCache) remove(elem *list.Element) {
+	entry := elem.Value.(*clientCacheEntry)
+	delete(c.entries, entry.key)
+	c.lru.Remove(elem)
+	clientCacheEvictions.Inc()
+	clientCacheEntries.Set(float64(c.lru.Len()))
+}
+
+// TokenHash returns the SHA-256 hash of a token, used to refer to a token without keeping it around
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CallerTokenHash returns the hash of the bearer token of the call, or an empty string when it has none
+func CallerTokenHash(ctx context.Context) string {
+	token, err := grpc_auth.AuthFromMD(ctx, "bearer")
+	if err != nil || token == "" {
+		return ""
+	}
+
+	return TokenHash(token)
+}
@@ -0,0 +1,547 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// unixSocketPrefix is the prefix of a listen address that is a unix socket
+const unixSocketPrefix = "unix://"
+
+// Config is the configuration of the server. It is read from the flags, the environment
+// variables and a YAML file, in that order of precedence
+type Config struct {
+	// Listen is a TCP address such as :8080, or a unix socket such as unix:///run/opencp.sock
+	Listen string `yaml:"listen"`
+
+	// HTTPListen is the TCP address of the HTTP endpoints such as /healthz, empty to turn them off
+	HTTPListen string `yaml:"httpListen"`
+
+	// LogLevel is one of the logrus levels, LogFormat is json or text
+	LogLevel  string `yaml:"logLevel"`
+	LogFormat string `yaml:"logFormat"`
+
+	// Backend is civo for the Civo API or memory for the in-memory backend
+	Backend string `yaml:"backend"`
+
+	// Region is the Civo region used when the call does not set one
+	Region string `yaml:"region"`
+
+	// CivoAPIURL is the base URL of the Civo API, it can point to a local stand-in
+	CivoAPIURL string `yaml:"civoApiUrl"`
+
+	Timeouts TimeoutConfig `yaml:"timeouts"`
+
+	TLS TLSConfig `yaml:"tls"`
+
+	Health HealthConfig `yaml:"health"`
+
+	Tracing TracingConfig `yaml:"tracing"`
+
+	RateLimits RateLimitConfig `yaml:"rateLimits"`
+
+	Retries RetryConfig `yaml:"retries"`
+
+	Audit AuditConfig `yaml:"audit"`
+
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+
+	// AllowRevealSecrets lets the callers the authorization policy allows ask for the database
+	// passwords and object storage secret keys in clear, they are masked otherwise
+	AllowRevealSecrets bool `yaml:"allowRevealSecrets"`
+
+	// ReadOnly rejects every call changing resources, ReadOnlyTokens only the calls made with the
+	// tokens of these SHA-256 hashes
+	ReadOnly       bool     `yaml:"readOnly"`
+	ReadOnlyTokens []string `yaml:"readOnlyTokens"`
+
+	// PolicyFile is the authorization policy binding roles to the callers, every caller can make
+	// every call when it is empty. The file is loaded again when it changes
+	PolicyFile string `yaml:"policyFile"`
+
+	// Services are the OpenCP services registered in the server, see ServiceNames
+	Services []string `yaml:"services"`
+}
+
+// TimeoutConfig are the timeouts of the server, zero means no timeout
+type TimeoutConfig struct {
+	// Request is the longest a read can take before it fails with DeadlineExceeded, the calls
+	// changing resources run to the end
+	Request Duration `yaml:"request"`
+
+	// Connection is the longest a new connection can take to be established
+	Connection Duration `yaml:"connection"`
+
+	// Idle is how long an idle connection is kept open
+	Idle Duration `yaml:"idle"`
+
+	// ShutdownGrace is how long the running calls have to finish when the server is stopped
+	ShutdownGrace Duration `yaml:"shutdownGrace"`
+}
+
+// HealthConfig is how the Civo API is probed to know if the server is serving
+type HealthConfig struct {
+	// ProbeInterval is the time between two probes, ProbeTimeout the longest a probe can take
+	ProbeInterval Duration `yaml:"probeInterval"`
+	ProbeTimeout  Duration `yaml:"probeTimeout"`
+}
+
+// Duration is a time.Duration written as a string such as 30s in the YAML file
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalYAML reads a duration such as 30s or 5m
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+// configOption is a setting that can be set with a flag and an environment variable
+type configOption struct {
+	flag  string
+	env   []string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+// configOptions are the settings that can be set with flags and environment variables
+var configOptions = []configOption{
+	{"listen", []string{"OPENCP_LISTEN"}, "address to listen on, host:port or unix:///path/to/socket", func(c *Config, v string) error {
+		c.Listen = v
+		return nil
+	}},
+	{"http-listen", []string{"OPENCP_HTTP_LISTEN"}, "address of the HTTP endpoints such as /healthz and /readyz, empty to turn them off", func(c *Config, v string) error {
+		c.HTTPListen = v
+		return nil
+	}},
+	{"log-level", []string{"OPENCP_LOG_LEVEL"}, "log level, one of panic, fatal, error, warn, info, debug or trace", func(c *Config, v string) error {
+		c.LogLevel = v
+		return nil
+	}},
+	{"log-format", []string{"OPENCP_LOG_FORMAT"}, "log format, json or text", func(c *Config, v string) error {
+		c.LogFormat = v
+		return nil
+	}},
+	{"backend", []string{"OPENCP_BACKEND"}, "backend serving the requests, civo for the Civo API or memory for an in-memory fake", func(c *Config, v string) error {
+		c.Backend = v
+		return nil
+	}},
+	{"region", []string{"OPENCP_REGION", "REGION"}, "Civo region used when the call does not set one", func(c *Config, v string) error {
+		c.Region = v
+		return nil
+	}},
+	{"civo-api-url", []string{"CIVO_API_URL"}, "base URL of the Civo API", func(c *Config, v string) error {
+		c.CivoAPIURL = v
+		return nil
+	}},
+	{"request-timeout", []string{"OPENCP_REQUEST_TIMEOUT"}, "longest a read can take, 0 for no timeout. The calls changing resources run to the end", func(c *Config, v string) error {
+		return setDuration(&c.Timeouts.Request, v)
+	}},
+	{"connection-timeout", []string{"OPENCP_CONNECTION_TIMEOUT"}, "longest a new connection can take to be established", func(c *Config, v string) error {
+		return setDuration(&c.Timeouts.Connection, v)
+	}},
+	{"idle-timeout", []string{"OPENCP_IDLE_TIMEOUT"}, "how long an idle connection is kept open, 0 for no limit", func(c *Config, v string) error {
+		return setDuration(&c.Timeouts.Idle, v)
+	}},
+	{"tls-cert", []string{"OPENCP_TLS_CERT"}, "PEM certificate file, turns on TLS", func(c *Config, v string) error {
+		c.TLS.CertFile = v
+		return nil
+	}},
+	{"tls-key", []string{"OPENCP_TLS_KEY"}, "PEM key file of the certificate", func(c *Config, v string) error {
+		c.TLS.KeyFile = v
+		return nil
+	}},
+	{"tls-client-ca", []string{"OPENCP_TLS_CLIENT_CA"}, "PEM bundle of the CAs of the client certificates, turns on mTLS", func(c *Config, v string) error {
+		c.TLS.ClientCAFile = v
+		return nil
+	}},
+	{"tls-client-auth", []string{"OPENCP_TLS_CLIENT_AUTH"}, "require or optional, whether the clients must have a certificate when mTLS is on", func(c *Config, v string) error {
+		c.TLS.ClientAuth = v
+		return nil
+	}},
+	{"health-probe-interval", []string{"OPENCP_HEALTH_PROBE_INTERVAL"}, "time between two probes of the Civo API", func(c *Config, v string) error {
+		return setDuration(&c.Health.ProbeInterval, v)
+	}},
+	{"health-probe-timeout", []string{"OPENCP_HEALTH_PROBE_TIMEOUT"}, "longest a probe of the Civo API can take", func(c *Config, v string) error {
+		return setDuration(&c.Health.ProbeTimeout, v)
+	}},
+	{"shutdown-grace-period", []string{"OPENCP_SHUTDOWN_GRACE_PERIOD"}, "how long the running calls have to finish when the server is stopped", func(c *Config, v string) error {
+		return setDuration(&c.Timeouts.ShutdownGrace, v)
+	}},
+	{"tracing-exporter", []string{"OPENCP_TRACING_EXPORTER"}, "where the spans are exported, one of none, otlp, stdout or file", func(c *Config, v string) error {
+		c.Tracing.Exporter = v
+		return nil
+	}},
+	{"tracing-otlp-endpoint", []string{"OPENCP_TRACING_OTLP_ENDPOINT"}, "host:port of the OTLP gRPC collector", func(c *Config, v string) error {
+		c.Tracing.OTLPEndpoint = v
+		return nil
+	}},
+	{"tracing-otlp-insecure", []string{"OPENCP_TRACING_OTLP_INSECURE"}, "true to connect to the OTLP collector without TLS", func(c *Config, v string) error {
+		insecure, err := strconv.ParseBool(v)
+		c.Tracing.OTLPInsecure = insecure
+		return err
+	}},
+	{"tracing-file", []string{"OPENCP_TRACING_FILE"}, "file the spans are written to by the file exporter", func(c *Config, v string) error {
+		c.Tracing.File = v
+		return nil
+	}},
+	{"tracing-sample-ratio", []string{"OPENCP_TRACING_SAMPLE_RATIO"}, "share of the traces started by the server that are kept, between 0 and 1", func(c *Config, v string) error {
+		ratio, err := strconv.ParseFloat(v, 64)
+		c.Tracing.SampleRatio = ratio
+		return err
+	}},
+	{"rate-limit-key", []string{"OPENCP_RATE_LIMIT_KEY"}, "what the rate limits are counted by, token or account", func(c *Config, v string) error {
+		c.RateLimits.Key = v
+		return nil
+	}},
+	{"rate-limit", []string{"OPENCP_RATE_LIMIT"}, "calls per second allowed to each caller, 0 for no limit", func(c *Config, v string) error {
+		limit, err := strconv.ParseFloat(v, 64)
+		c.RateLimits.Default.Rate = limit
+		return err
+	}},
+	{"rate-limit-burst", []string{"OPENCP_RATE_LIMIT_BURST"}, "calls a caller can make at once above the rate limit", func(c *Config, v string) error {
+		burst, err := strconv.Atoi(v)
+		c.RateLimits.Default.Burst = burst
+		return err
+	}},
+	{"max-concurrent-calls", []string{"OPENCP_MAX_CONCURRENT_CALLS"}, "calls each caller can have running at once, 0 for no limit", func(c *Config, v string) error {
+		concurrency, err := strconv.Atoi(v)
+		c.RateLimits.Default.Concurrency = concurrency
+		return err
+	}},
+	{"retry-max-attempts", []string{"OPENCP_RETRY_MAX_ATTEMPTS"}, "most times a failed Civo API call is tried, 1 to turn the retries off", func(c *Config, v string) error {
+		attempts, err := strconv.Atoi(v)
+		c.Retries.MaxAttempts = attempts
+		return err
+	}},
+	{"retry-initial-backoff", []string{"OPENCP_RETRY_INITIAL_BACKOFF"}, "wait before the first retry of a Civo API call, doubled for each retry", func(c *Config, v string) error {
+		return setDuration(&c.Retries.InitialBackoff, v)
+	}},
+	{"retry-max-backoff", []string{"OPENCP_RETRY_MAX_BACKOFF"}, "longest wait between two tries of a Civo API call", func(c *Config, v string) error {
+		return setDuration(&c.Retries.MaxBackoff, v)
+	}},
+	{"audit-sinks", []string{"OPENCP_AUDIT_SINKS"}, "comma separated list of where the audit records are written, of " + strings.Join(AuditSinkNames(), ", ") + ", empty to turn the audit off", func(c *Config, v string) error {
+		c.Audit.Sinks = splitList(v)
+		return nil
+	}},
+	{"audit-file", []string{"OPENCP_AUDIT_FILE"}, "JSONL file of the file audit sink", func(c *Config, v string) error {
+		c.Audit.File = v
+		return nil
+	}},
+	{"audit-file-max-size", []string{"OPENCP_AUDIT_FILE_MAX_SIZE"}, "size in MB at which the audit file is rotated", func(c *Config, v string) error {
+		size, err := strconv.Atoi(v)
+		c.Audit.MaxSizeMB = size
+		return err
+	}},
+	{"audit-file-max-backups", []string{"OPENCP_AUDIT_FILE_MAX_BACKUPS"}, "number of rotated audit files kept", func(c *Config, v string) error {
+		backups, err := strconv.Atoi(v)
+		c.Audit.MaxBackups = backups
+		return err
+	}},
+	{"idempotency-window", []string{"OPENCP_IDEMPOTENCY_WINDOW"}, "how long the answers of the calls with an idempotency key are remembered, 0 to turn the idempotency keys off", func(c *Config, v string) error {
+		return setDuration(&c.Idempotency.Window, v)
+	}},
+	{"idempotency-max-keys", []string{"OPENCP_IDEMPOTENCY_MAX_KEYS"}, "most idempotency keys remembered, the oldest are forgotten first", func(c *Config, v string) error {
+		keys, err := strconv.Atoi(v)
+		c.Idempotency.MaxKeys = keys
+		return err
+	}},
+	{"allow-reveal-secrets", []string{"OPENCP_ALLOW_REVEAL_SECRETS"}, "true to let the callers the authorization policy allows see the secrets in clear, needs an audit sink", func(c *Config, v string) error {
+		allow, err := strconv.ParseBool(v)
+		c.AllowRevealSecrets = allow
+		return err
+	}},
+	{"read-only", []string{"OPENCP_READ_ONLY"}, "true to reject every Create, Delete and Update call and every power action", func(c *Config, v string) error {
+		readOnly, err := strconv.ParseBool(v)
+		c.ReadOnly = readOnly
+		return err
+	}},
+	{"read-only-tokens", []string{"OPENCP_READ_ONLY_TOKENS"}, "comma separated list of the SHA-256 hashes of the tokens that can't Create, Delete, Update or run power actions", func(c *Config, v string) error {
+		c.ReadOnlyTokens = splitList(v)
+		return nil
+	}},
+	{"policy-file", []string{"OPENCP_POLICY_FILE"}, "path to the authorization policy file, empty to let every caller make every call", func(c *Config, v string) error {
+		c.PolicyFile = v
+		return nil
+	}},
+	{"services", []string{"OPENCP_SERVICES"}, "comma separated list of the services to serve, one of " + strings.Join(ServiceNames(), ", "), func(c *Config, v string) error {
+		c.Services = splitList(v)
+		return nil
+	}},
+}
+
+// DefaultConfig returns the configuration used for the settings that are not set
+func DefaultConfig() *Config {
+	return &Config{
+		Listen:     ":8080",
+		HTTPListen: ":8081",
+		LogLevel:   "info",
+		LogFormat:  "json",
+		Backend:    "civo",
+		CivoAPIURL: CivoAPIURL,
+		Timeouts: TimeoutConfig{
+			Request:       Duration{2 * time.Minute},
+			Connection:    Duration{30 * time.Second},
+			ShutdownGrace: Duration{30 * time.Second},
+		},
+		TLS: TLSConfig{
+			ClientAuth: "require",
+		},
+		Health: HealthConfig{
+			ProbeInterval: Duration{30 * time.Second},
+			ProbeTimeout:  Duration{5 * time.Second},
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+		},
+		RateLimits: RateLimitConfig{
+			Key: "token",
+		},
+		Retries: RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: Duration{250 * time.Millisecond},
+			MaxBackoff:     Duration{5 * time.Second},
+		},
+		Audit: AuditConfig{
+			Sinks:      []string{"stdout"},
+			MaxSizeMB:  100,
+			MaxBackups: 10,
+		},
+		Idempotency: IdempotencyConfig{
+			Window:  Duration{24 * time.Hour},
+			MaxKeys: 10000,
+		},
+		Services: ServiceNames(),
+	}
+}
+
+// LoadConfig reads the configuration from the command line arguments, the environment variables
+// and the YAML file given with --config or OPENCP_CONFIG
+func LoadConfig(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("civo-opencp", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("OPENCP_CONFIG"), "path of the YAML config file")
+
+	flags := map[string]*string{}
+	for _, option := range configOptions {
+		usage := option.usage
+		if len(option.env) > 0 {
+			usage += fmt.Sprintf(" (env %s)", strings.Join(option.env, ", "))
+		}
+		flags[option.flag] = fs.String(option.flag, "", usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	config := DefaultConfig()
+
+	// The config file
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading the config file: %w", err)
+		}
+
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("parsing the config file %s: %w", *configFile, err)
+		}
+	}
+
+	// The environment variables
+	for _, option := range configOptions {
+		for _, env := range option.env {
+			if value, ok := os.LookupEnv(env); ok {
+				if err := option.set(config, value); err != nil {
+					return nil, fmt.Errorf("invalid %s: %w", env, err)
+				}
+				break
+			}
+		}
+	}
+
+	// The flags set in the command line
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		value, ok := flags[f.Name]
+		if !ok || flagErr != nil {
+			return
+		}
+
+		for _, option := range configOptions {
+			if option.flag == f.Name {
+				if err := option.set(config, *value); err != nil {
+					flagErr = fmt.Errorf("invalid --%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Validate checks every setting, returning all the problems found at once
+func (c *Config) Validate() error {
+	problems := []string{}
+
+	if strings.HasPrefix(c.Listen, unixSocketPrefix) {
+		if strings.TrimPrefix(c.Listen, unixSocketPrefix) == "" {
+			problems = append(problems, fmt.Sprintf("listen %q has no socket path", c.Listen))
+		}
+	} else if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+		problems = append(problems, fmt.Sprintf("listen %q must be host:port or unix:///path: %v", c.Listen, err))
+	}
+
+	if c.HTTPListen != "" {
+		if _, _, err := net.SplitHostPort(c.HTTPListen); err != nil {
+			problems = append(problems, fmt.Sprintf("HTTP listen %q must be host:port: %v", c.HTTPListen, err))
+		}
+	}
+
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		problems = append(problems, fmt.Sprintf("log level: %v", err))
+	}
+
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		problems = append(problems, fmt.Sprintf("log format %q must be json or text", c.LogFormat))
+	}
+
+	if c.Backend != "civo" && c.Backend != "memory" {
+		problems = append(problems, fmt.Sprintf("backend %q must be civo or memory", c.Backend))
+	}
+
+	if u, err := url.Parse(c.CivoAPIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("Civo API URL %q must be an http or https URL", c.CivoAPIURL))
+	}
+
+	timeouts := []struct {
+		name    string
+		timeout Duration
+	}{
+		{"request", c.Timeouts.Request},
+		{"connection", c.Timeouts.Connection},
+		{"idle", c.Timeouts.Idle},
+		{"shutdown grace", c.Timeouts.ShutdownGrace},
+	}
+	for _, t := range timeouts {
+		if t.timeout.Duration < 0 {
+			problems = append(problems, fmt.Sprintf("%s timeout %s can't be negative", t.name, t.timeout))
+		}
+	}
+
+	if c.Health.ProbeInterval.Duration <= 0 || c.Health.ProbeTimeout.Duration <= 0 {
+		problems = append(problems, "the health probe interval and timeout must be positive")
+	}
+
+	problems = append(problems, c.TLS.validate()...)
+	problems = append(problems, c.Tracing.validate()...)
+	problems = append(problems, c.RateLimits.validate()...)
+	problems = append(problems, c.Retries.validate()...)
+	problems = append(problems, c.Audit.validate()...)
+	problems = append(problems, c.Idempotency.validate()...)
+
+	if c.AllowRevealSecrets && len(c.Audit.Sinks) == 0 {
+		problems = append(problems, "revealing the secrets needs an audit sink to record the reveals")
+	}
+
+	for _, hash := range c.ReadOnlyTokens {
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+			problems = append(problems, fmt.Sprintf("read-only token %q must be the hex SHA-256 hash of a token", hash))
+		}
+	}
+
+	if len(c.Services) == 0 {
+		problems = append(problems, "no service is enabled")
+	}
+	for _, name := range c.Services {
+		if _, ok := services[name]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown service %q, must be one of %s", name, strings.Join(ServiceNames(), ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Listener opens the TCP address or unix socket the server listens on
+func (c *Config) Listener() (net.Listener, error) {
+	if strings.HasPrefix(c.Listen, unixSocketPrefix) {
+		path := strings.TrimPrefix(c.Listen, unixSocketPrefix)
+
+		// Remove the socket left behind by a previous run
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+
+		return net.Listen("unix", path)
+	}
+
+	return net.Listen("tcp", c.Listen)
+}
+
+// Formatter returns the logrus formatter of the log format
+func (c *Config) Formatter() logrus.Formatter {
+	if c.LogFormat == "text" {
+		return &logrus.TextFormatter{FullTimestamp: true}
+	}
+
+	return &logrus.JSONFormatter{}
+}
+
+// setDuration parses a duration such as 30s
+func setDuration(d *Duration, value string) error {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+// splitList splits a comma separated list, dropping the empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := "listen: :7000\nlogLevel: warn\nlogFormat: text\nregion: NYC1\ntimeouts:\n  request: 10s\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatalf("writing the config file: %v", err)
+	}
+
+	// OPENCP_REGION wins over the older REGION, so it must not be set for REGION to be used
+	t.Setenv("OPENCP_REGION", "")
+	os.Unsetenv("OPENCP_REGION")
+	t.Setenv("REGION", "FRA1")
+	t.Setenv("OPENCP_LOG_LEVEL", "debug")
+	t.Setenv("OPENCP_LISTEN", ":7001")
+
+	config, err := LoadConfig([]string{"--config", path, "--listen", ":7002"})
+	if err != nil {
+		t.Fatalf("loading the config: %v", err)
+	}
+
+	checks := []struct {
+		setting, got, want string
+	}{
+		{"listen, set everywhere", config.Listen, ":7002"},
+		{"logLevel, set in the file and the environment", config.LogLevel, "debug"},
+		{"region, set in the file and the older environment variable", config.Region, "FRA1"},
+		{"logFormat, set in the file", config.LogFormat, "text"},
+		{"httpListen, set nowhere", config.HTTPListen, ":8081"},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s: got %q, want %q", check.setting, check.got, check.want)
+		}
+	}
+	if config.Timeouts.Request.Duration != 10*time.Second {
+		t.Errorf("timeouts.request, set in the file: got %v, want 10s", config.Timeouts.Request.Duration)
+	}
+}
+
+func TestLoadConfigReportsEveryProblem(t *testing.T) {
+	_, err := LoadConfig([]string{"--log-level", "loud", "--log-format", "xml"})
+	if err == nil {
+		t.Fatal("loading an invalid config succeeded")
+	}
+	for _, setting := range []string{"loud", "xml"} {
+		if !strings.Contains(err.Error(), setting) {
+			t.Errorf("the error %q does not report the invalid %s", err, setting)
+		}
+	}
+}
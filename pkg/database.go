@@ -12,7 +12,7 @@ import (
 
 func (s *Server) ListDatabase(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.DatabaseList, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// List all databases
 	dbList, err := client.ListDatabases()
@@ -102,7 +102,7 @@ func (s *Server) ListDatabase(ctx context.Context, option *opencpspec.FilterOpti
 
 func (s *Server) GetDatabase(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Database, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Find the database
 	db, err := client.FindDatabase(*option.Name)
@@ -120,7 +120,7 @@ func (s *Server) GetDatabase(ctx context.Context, option *opencpspec.FilterOptio
 
 		// filter the firewall by network
 		if db.NetworkID != string(network.Metadata.UID) {
-			return nil, nil
+			return nil, notFoundError("database", db.Name)
 		}
 
 		// set the network name
@@ -159,7 +159,11 @@ func (s *Server) GetDatabase(ctx context.Context, option *opencpspec.FilterOptio
 
 func (s *Server) CreateDatabase(ctx context.Context, in *opencpspec.Database) (*opencpspec.Database, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
+
+	if err := validateDatabase(in); err != nil {
+		return nil, err
+	}
 
 	// get the network
 	network, err := s.GetNamespace(ctx, &opencpspec.FilterOptions{Name: &in.Metadata.Namespace})
@@ -173,7 +177,7 @@ func (s *Server) CreateDatabase(ctx context.Context, in *opencpspec.Database) (*
 		Size:       in.Spec.Size,
 		NetworkID:  string(network.Metadata.UID),
 		Nodes:      int(in.Spec.Nodes),
-		Region:     client.Region,
+		Region:     client.GetRegion(),
 	}
 
 	// Check if the incoming database have firewall
@@ -186,6 +190,20 @@ func (s *Server) CreateDatabase(ctx context.Context, in *opencpspec.Database) (*
 		dbConfig.FirewallID = string(firewall.Metadata.UID)
     }
 
+	// Return the database if it was already created
+	existing, err := s.existingDatabase(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	// Stop before creating anything in a dry run
+	if isDryRun(ctx) {
+		return dryRunDatabase(client, in, network.Metadata.Name)
+	}
+
 	// Create the database using the civo client
 	_, err = client.NewDatabase(dbConfig)
 	if err != nil {
@@ -198,7 +216,7 @@ func (s *Server) CreateDatabase(ctx context.Context, in *opencpspec.Database) (*
 
 func (s *Server) DeleteDatabase(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Database, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Find the database using the opencp
 	db, err := s.GetDatabase(ctx, option)
@@ -206,6 +224,10 @@ func (s *Server) DeleteDatabase(ctx context.Context, option *opencpspec.FilterOp
         return nil, err
     }
 
+	if isDryRun(ctx) {
+		return db, nil
+	}
+
     // Delete the database
     _, err = client.DeleteDatabase(string(db.Metadata.UID))
     if err!= nil {
@@ -215,3 +237,63 @@ func (s *Server) DeleteDatabase(ctx context.Context, option *opencpspec.FilterOp
 	// Return the database in the opencp format
     return db, nil
 }
+
+// validateDatabase checks the fields a database needs to be created
+func validateDatabase(in *opencpspec.Database) error {
+	if err := validateObjectMeta("database", in.Metadata); err != nil {
+		return err
+	}
+	if in.Spec == nil || in.Spec.Size == "" {
+		return invalidArgument("the size of database %q is required", in.Metadata.Name)
+	}
+	if in.Spec.Nodes < 0 {
+		return invalidArgument("the nodes of database %q can't be negative", in.Metadata.Name)
+	}
+
+	return nil
+}
+
+// existingDatabase returns the database with the name of in in its namespace, nil if there is none,
+// or AlreadyExists if it does not match in
+func (s *Server) existingDatabase(ctx context.Context, in *opencpspec.Database) (*opencpspec.Database, error) {
+	databases, err := s.ListDatabase(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingResource("database", in.Metadata, databases.Items, func(db *opencpspec.Database) specDiff {
+		diff := specDiff{}
+		diff.compare("size", in.Spec.Size, db.Spec.Size)
+		if in.Spec.Nodes > 0 && in.Spec.Nodes != db.Spec.Nodes {
+			diff = append(diff, "nodes")
+		}
+		diff.compare("engine", in.Spec.Engine, db.Spec.Engine)
+		diff.compare("engineVersion", in.Spec.EngineVersion, db.Spec.EngineVersion)
+		diff.compare("firewall", in.Spec.Firewall, db.Spec.Firewall)
+		return diff
+	})
+}
+
+// dryRunDatabase checks what the Civo API would refuse and returns the database that would be created
+func dryRunDatabase(client Provider, in *opencpspec.Database, namespace string) (*opencpspec.Database, error) {
+	if err := validateSize(client, "database", in.Spec.Size); err != nil {
+		return nil, err
+	}
+
+	return &opencpspec.Database{
+		Metadata: &metav1.ObjectMeta{
+			Name:      in.Metadata.Name,
+			Namespace: namespace,
+		},
+		Spec: &opencpspec.DatabaseSpec{
+			Nodes:         in.Spec.Nodes,
+			Size:          in.Spec.Size,
+			Engine:        in.Spec.Engine,
+			EngineVersion: in.Spec.EngineVersion,
+			Firewall:      in.Spec.Firewall,
+		},
+		Status: &opencpspec.DatabaseStatus{
+			State: dryRunState,
+		},
+	}, nil
+}
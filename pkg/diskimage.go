@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	civov1 "github.com/civo/civo-opencp/api/civo/v1"
+	"github.com/civo/civogo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// diskImageAvailable is the state of the disk images the virtual machines can be created from
+const diskImageAvailable = "available"
+
+// ListDiskImage returns the disk images of the region with their aliases, the newest versions first
+func (s *Server) ListDiskImage(ctx context.Context, in *civov1.ListDiskImageRequest) (*civov1.DiskImageList, error) {
+	client := ctx.Value("client").(Provider)
+
+	images, err := client.ListDiskImages()
+	if err != nil {
+		return nil, err
+	}
+	sortDiskImages(images)
+
+	list := &civov1.DiskImageList{}
+	for _, image := range images {
+		if in.Distribution != "" && !strings.EqualFold(image.Distribution, in.Distribution) {
+			continue
+		}
+
+		list.Items = append(list.Items, &civov1.DiskImage{
+			Id:           image.ID,
+			Name:         image.Name,
+			Distribution: image.Distribution,
+			Version:      image.Version,
+			Label:        image.Label,
+			State:        image.State,
+			Aliases:      diskImageAliases(images, image),
+		})
+	}
+
+	return list, nil
+}
+
+// resolveDiskImage returns the disk image with the ID or the name, or the newest available one matching an alias:
+// the distribution as ubuntu, the distribution and its version as debian:11 or ubuntu:22, or the release as jammy
+func resolveDiskImage(client Provider, image string) (*civogo.DiskImage, error) {
+	images, err := client.ListDiskImages()
+	if err != nil {
+		return nil, err
+	}
+	sortDiskImages(images)
+
+	for i := range images {
+		if images[i].ID == image || images[i].Name == image {
+			return &images[i], nil
+		}
+	}
+
+	if resolved := matchDiskImageAlias(images, image); resolved != nil {
+		return resolved, nil
+	}
+
+	return nil, status.Errorf(codes.NotFound, "disk image %q not found, DiskImageService/ListDiskImage lists the images and their aliases", image)
+}
+
+// matchDiskImageAlias returns the first available image of the sorted images matching the alias, nil if there is none
+func matchDiskImageAlias(images []civogo.DiskImage, alias string) *civogo.DiskImage {
+	alias = strings.ToLower(alias)
+	for i, image := range images {
+		if image.State != diskImageAvailable {
+			continue
+		}
+
+		distribution := strings.ToLower(image.Distribution)
+		label := strings.ToLower(image.Label)
+		version := strings.TrimPrefix(alias, distribution+":")
+		switch {
+		case alias == distribution,
+			label != "" && (alias == label || alias == distribution+":"+label),
+			version != alias && (version == image.Version || strings.HasPrefix(image.Version, version+".")):
+			return &images[i]
+		}
+	}
+
+	return nil
+}
+
+// diskImageAliases returns the aliases resolving to the image
+func diskImageAliases(images []civogo.DiskImage, image civogo.DiskImage) []string {
+	distribution := strings.ToLower(image.Distribution)
+	candidates := []string{distribution, distribution + ":" + image.Version}
+	if major, _, ok := strings.Cut(image.Version, "."); ok {
+		candidates = append(candidates, distribution+":"+major)
+	}
+	if image.Label != "" {
+		candidates = append(candidates, strings.ToLower(image.Label))
+	}
+
+	aliases := []string{}
+	for _, alias := range candidates {
+		if alias == image.Name {
+			continue
+		}
+		if resolved := matchDiskImageAlias(images, alias); resolved != nil && resolved.ID == image.ID {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
+
+// sortDiskImages sorts the images by distribution, the newest versions first
+func sortDiskImages(images []civogo.DiskImage) {
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].Distribution != images[j].Distribution {
+			return images[i].Distribution < images[j].Distribution
+		}
+		return compareVersions(images[i].Version, images[j].Version) > 0
+	})
+}
+
+// compareVersions compares two dotted versions part by part, numerically when the parts are numbers
+func compareVersions(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		numA, errA := strconv.Atoi(partsA[i])
+		numB, errB := strconv.Atoi(partsB[i])
+		switch {
+		case errA == nil && errB == nil && numA != numB:
+			if numA > numB {
+				return 1
+			}
+			return -1
+		case (errA != nil || errB != nil) && partsA[i] != partsB[i]:
+			return strings.Compare(partsA[i], partsB[i])
+		}
+	}
+
+	return len(partsA) - len(partsB)
+}
+
+// diskImageNames returns the names of the disk images by their ID
+func diskImageNames(client Provider) (map[string]string, error) {
+	images, err := client.ListDiskImages()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, image := range images {
+		names[image.ID] = image.Name
+	}
+
+	return names, nil
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	civov1 "github.com/civo/civo-opencp/api/civo/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolveDiskImage(t *testing.T) {
+	client, err := NewMemoryBackend().Provider("image-token", "LON1")
+	if err != nil {
+		t.Fatalf("creating the memory provider: %v", err)
+	}
+
+	tests := map[string]string{
+		"ubuntu-focal":                         "ubuntu-focal",
+		"a4204155-a876-43fa-b4d6-ea2af8774560": "debian-11",
+		"ubuntu":                               "ubuntu-jammy",
+		"Ubuntu:20":                            "ubuntu-focal",
+		"ubuntu:22.04":                         "ubuntu-jammy",
+		"debian:11":                            "debian-11",
+		"buster":                               "debian-10",
+		"debian:bullseye":                      "debian-11",
+		"rocky:9":                              "rocky-9-1",
+	}
+	for image, want := range tests {
+		resolved, err := resolveDiskImage(client, image)
+		if err != nil {
+			t.Errorf("resolving %s: %v", image, err)
+			continue
+		}
+		if resolved.Name != want {
+			t.Errorf("%s resolved to %s, want %s", image, resolved.Name, want)
+		}
+	}
+
+	for _, image := range []string{"ubuntu:2", "windows", "debian:12"} {
+		if _, err := resolveDiskImage(client, image); status.Code(err) != codes.NotFound {
+			t.Errorf("resolving %s: got %v, want NotFound", image, err)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"22.04", "20.04", 1},
+		{"9.1", "10", -1},
+		{"11", "11", 0},
+		{"9.1", "9", 1},
+		{"rc2", "rc10", 1},
+	}
+	for _, test := range tests {
+		got := compareVersions(test.a, test.b)
+		if (got > 0) != (test.want > 0) || (got < 0) != (test.want < 0) {
+			t.Errorf("comparing %s and %s: got %d, want the sign of %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestListDiskImage(t *testing.T) {
+	images := civov1.NewDiskImageServiceClient(newTestConn(t))
+
+	list, err := images.ListDiskImage(withToken("image-token"), &civov1.ListDiskImageRequest{Distribution: "Ubuntu"})
+	if err != nil {
+		t.Fatalf("listing the ubuntu disk images: %v", err)
+	}
+	if len(list.Items) != 2 || list.Items[0].Name != "ubuntu-jammy" || list.Items[1].Name != "ubuntu-focal" {
+		t.Fatalf("got the images %v, want ubuntu-jammy then ubuntu-focal", list.Items)
+	}
+
+	// The distribution alias only goes to the newest image
+	if aliases := strings.Join(list.Items[0].Aliases, " "); aliases != "ubuntu ubuntu:22.04 ubuntu:22 jammy" {
+		t.Errorf("ubuntu-jammy has the aliases %q", aliases)
+	}
+	if aliases := strings.Join(list.Items[1].Aliases, " "); aliases != "ubuntu:20.04 ubuntu:20 focal" {
+		t.Errorf("ubuntu-focal has the aliases %q", aliases)
+	}
+}
@@ -12,7 +12,7 @@ import (
 
 func (s *Server) ListDomains(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.DomainList, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all the domains again and return them
 	allDomains, err := client.ListDNSDomains()
@@ -65,7 +65,7 @@ func (s *Server) ListDomains(ctx context.Context, option *opencpspec.FilterOptio
 
 func (s *Server) CreateDomain(ctx context.Context, in *opencpspec.Domain) (*opencpspec.Domain, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Create the Domain
 	domainResult, err := client.CreateDNSDomain(in.Metadata.Name)
@@ -103,7 +103,7 @@ func (s *Server) CreateDomain(ctx context.Context, in *opencpspec.Domain) (*open
 
 func (s *Server) GetDomain(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Domain, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the domain
 	domainResult, err := client.FindDNSDomain(*option.Name)
@@ -152,7 +152,7 @@ func (s *Server) GetDomain(ctx context.Context, option *opencpspec.FilterOptions
 
 func (s *Server) DeleteDomain(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Domain, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the domain
 	domainResult, err := client.FindDNSDomain(*option.Name)
@@ -166,6 +166,10 @@ func (s *Server) DeleteDomain(ctx context.Context, option *opencpspec.FilterOpti
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return domain, nil
+	}
+
 	// Delete the domain
 	_, err = client.DeleteDNSDomain(domainResult)
 	if err != nil {
@@ -0,0 +1,162 @@
+package pkg
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DryRunMetadataKey is the metadata key asking to check a create or a delete without running it, e.g. true
+const DryRunMetadataKey = "x-opencp-dry-run"
+
+// dryRunState is the state of the objects answered by a dry run create
+const dryRunState = "DryRun"
+
+// dryRunMethods are the creates, updates and power actions that can be dry run, every delete can
+var dryRunMethods = map[string]bool{
+	"CreateVirtualMachine":     true,
+	"UpdateVirtualMachine":     true,
+	"StartVirtualMachine":      true,
+	"StopVirtualMachine":       true,
+	"SoftRebootVirtualMachine": true,
+	"HardRebootVirtualMachine": true,
+	"CreateKubernetesCluster":  true,
+	"CreateDatabase":           true,
+	"CreateFirewall":           true,
+}
+
+// DryRunUnaryServerInterceptor rejects the dry runs of the methods changing resources that can't be
+// dry run, so they are never run for real. The handlers of the others check the call and stop before
+// changing anything
+func DryRunUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_, method := splitMethod(info.FullMethod)
+		if isDryRun(ctx) && isMutatingMethod(method) && !dryRunMethods[method] && !strings.HasPrefix(method, "Delete") {
+			return nil, status.Errorf(codes.InvalidArgument, "%s can't be dry run", method)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// isDryRun returns true if the caller asked for a dry run
+func isDryRun(ctx context.Context) bool {
+	return metadataFlag(ctx, DryRunMetadataKey)
+}
+
+// metadataFlag returns the boolean value of the metadata key of the call, false when it is not set
+func metadataFlag(ctx context.Context, key string) bool {
+	value, _ := strconv.ParseBool(metadataValue(ctx, key))
+	return value
+}
+
+// metadataValue returns the first value of the metadata key of the call, empty when it is not set
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// invalidArgument returns an InvalidArgument status for a call that can't succeed
+func invalidArgument(format string, args ...interface{}) error {
+	return status.Errorf(codes.InvalidArgument, format, args...)
+}
+
+// validateObjectMeta checks that a resource to create has a name and a namespace
+func validateObjectMeta(kind string, meta *metav1.ObjectMeta) error {
+	if meta == nil || meta.Name == "" {
+		return invalidArgument("the name of the %s is required", kind)
+	}
+	if meta.Namespace == "" {
+		return invalidArgument("the namespace of %s %q is required", kind, meta.Name)
+	}
+
+	return nil
+}
+
+// validateFirewallRules checks the protocol, the action and the sources of the rules
+func validateFirewallRules(direction string, rules []*opencpspec.FirewallRules) error {
+	for i, rule := range rules {
+		if rule == nil {
+			return invalidArgument("%s rule %d is empty", direction, i)
+		}
+
+		switch strings.ToLower(rule.Protocol) {
+		case "", "tcp", "udp", "icmp":
+		default:
+			return invalidArgument("%s rule %d: protocol %q must be tcp, udp or icmp", direction, i, rule.Protocol)
+		}
+
+		switch strings.ToLower(rule.Action) {
+		case "", "allow", "deny":
+		default:
+			return invalidArgument("%s rule %d: action %q must be allow or deny", direction, i, rule.Action)
+		}
+
+		for _, source := range rule.Source {
+			if _, _, err := net.ParseCIDR(source); err != nil && net.ParseIP(source) == nil {
+				return invalidArgument("%s rule %d: source %q is not an IP address or a CIDR", direction, i, source)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSize checks that the size of a resource is one of the Civo sizes
+func validateSize(client Provider, kind, size string) error {
+	sizes, err := client.ListInstanceSizes()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(sizes))
+	for _, s := range sizes {
+		if s.Name == size {
+			return nil
+		}
+		if s.Selectable {
+			names = append(names, s.Name)
+		}
+	}
+
+	return invalidArgument("size %q of the %s is not valid, must be one of %s", size, kind, strings.Join(names, ", "))
+}
+
+// validateKubernetesVersion checks that the version is one Civo can install, an empty version is the default
+func validateKubernetesVersion(client Provider, version string) error {
+	if version == "" {
+		return nil
+	}
+
+	versions, err := client.ListAvailableKubernetesVersions()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if v.Version == version || v.Label == version {
+			return nil
+		}
+		names = append(names, v.Version)
+	}
+
+	return invalidArgument("kubernetes version %q is not available, must be one of %s", version, strings.Join(names, ", "))
+}
@@ -0,0 +1,213 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/civo/civogo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the domain reported in the ErrorInfo details of every translated error
+const errorDomain = "api.civo.com"
+
+// civoErrorCodes maps the typed errors from civogo to the gRPC code we return
+var civoErrorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{civogo.ZeroMatchesError, codes.NotFound},
+	{civogo.MultipleMatchesError, codes.InvalidArgument},
+	{civogo.IDisEmptyError, codes.InvalidArgument},
+	{civogo.NoAPIKeySuppliedError, codes.Unauthenticated},
+	{civogo.AuthenticationError, codes.Unauthenticated},
+	{civogo.AuthenticationFailedError, codes.Unauthenticated},
+	{civogo.AuthenticationInvalidKeyError, codes.Unauthenticated},
+	{civogo.AuthenticationAccessDeniedError, codes.PermissionDenied},
+	{civogo.DatabaseAccountAccessDeniedError, codes.PermissionDenied},
+	{civogo.AccountNotEnabledIncCardError, codes.PermissionDenied},
+	{civogo.AccountNotEnabledWithoutCardError, codes.PermissionDenied},
+	{civogo.QuotaLimitReachedError, codes.ResourceExhausted},
+	{civogo.OutOFCapacityError, codes.ResourceExhausted},
+	{civogo.DatabaseClusterPoolNoSufficientInstancesAvailableError, codes.ResourceExhausted},
+	{civogo.FirewallDuplicateError, codes.AlreadyExists},
+	{civogo.SSHKeyDuplicateError, codes.AlreadyExists},
+	{civogo.TimeoutError, codes.Unavailable},
+	{civogo.RegionUnavailableError, codes.Unavailable},
+	{civogo.DisabledServiceError, codes.Unavailable},
+	{civogo.InternalServerError, codes.Unavailable},
+}
+
+// civoReasonCodes is used for the civogo errors not listed above, matching on the error name
+var civoReasonCodes = []struct {
+	match func(reason string) bool
+	code  codes.Code
+}{
+	{func(r string) bool { return strings.Contains(r, "NotFound") }, codes.NotFound},
+	{func(r string) bool { return strings.Contains(r, "Duplicate") || strings.Contains(r, "Exists") }, codes.AlreadyExists},
+	{func(r string) bool { return strings.HasPrefix(r, "Parameter") || strings.Contains(r, "Invalid") }, codes.InvalidArgument},
+	{func(r string) bool { return strings.HasPrefix(r, "Openstack") }, codes.Unavailable},
+}
+
+var (
+	// civoReasonRegexp extracts the error name civogo puts in front of every error message
+	civoReasonRegexp = regexp.MustCompile(`^([A-Z][A-Za-z0-9]+): `)
+
+	// civoHTTPCodeRegexp extracts the HTTP status civogo keeps in the message of undecoded errors
+	civoHTTPCodeRegexp = regexp.MustCompile(`code: (\d{3})`)
+)
+
+// ErrorUnaryServerInterceptor translates the errors returned by the handlers into gRPC status errors
+func ErrorUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, CivoErrorToStatus(err)
+		}
+
+		return resp, nil
+	}
+}
+
+// ErrorStreamServerInterceptor translates the errors returned by the stream handlers into gRPC status errors
+func ErrorStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, stream); err != nil {
+			return CivoErrorToStatus(err)
+		}
+
+		return nil
+	}
+}
+
+// CivoErrorToStatus converts an error from civogo into a gRPC status error,
+// keeping the Civo error reason and HTTP status in the status details
+func CivoErrorToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Errors that are already a status are returned as they are
+	if s, ok := status.FromError(err); ok {
+		return s.Err()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	reason := civoErrorReason(err)
+	httpCode := civoHTTPStatus(err)
+
+	code := civoErrorCode(err, reason, httpCode)
+
+	info := &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   errorDomain,
+		Metadata: map[string]string{},
+	}
+	if httpCode != 0 {
+		info.Metadata["http_status"] = strconv.Itoa(httpCode)
+	}
+
+	s, detailErr := status.New(code, err.Error()).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+
+	return s.Err()
+}
+
+// notFoundError returns a NotFound status for a resource that does not exist
+func notFoundError(kind, name string) error {
+	return status.Errorf(codes.NotFound, "%s %q not found", kind, name)
+}
+
+// civoErrorCode picks the gRPC code for an error, the typed civogo errors win over the HTTP status
+func civoErrorCode(err error, reason string, httpCode int) codes.Code {
+	for _, e := range civoErrorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+
+	if httpCode != 0 {
+		if code, ok := httpStatusCode(httpCode); ok {
+			return code
+		}
+	}
+
+	for _, r := range civoReasonCodes {
+		if r.match(reason) {
+			return r.code
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return codes.Unavailable
+	}
+
+	return codes.Unknown
+}
+
+// httpStatusCode maps the HTTP status of the Civo API to a gRPC code
+func httpStatusCode(httpCode int) (codes.Code, bool) {
+	switch {
+	case httpCode == 400 || httpCode == 422:
+		return codes.InvalidArgument, true
+	case httpCode == 401:
+		return codes.Unauthenticated, true
+	case httpCode == 403:
+		return codes.PermissionDenied, true
+	case httpCode == 404:
+		return codes.NotFound, true
+	case httpCode == 409:
+		return codes.AlreadyExists, true
+	case httpCode == 429:
+		return codes.ResourceExhausted, true
+	case httpCode >= 500:
+		return codes.Unavailable, true
+	}
+
+	return codes.Unknown, false
+}
+
+// civoErrorReason returns the name of the civogo error, e.g. DatabaseInstanceNotFoundError
+func civoErrorReason(err error) string {
+	var httpErr civogo.HTTPError
+	if errors.As(err, &httpErr) {
+		return "HTTPError"
+	}
+
+	if match := civoReasonRegexp.FindStringSubmatch(err.Error()); match != nil {
+		return match[1]
+	}
+
+	return "UnknownError"
+}
+
+// civoHTTPStatus returns the HTTP status of the Civo API call, or 0 if it is not known
+func civoHTTPStatus(err error) int {
+	var httpErr civogo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+
+	if match := civoHTTPCodeRegexp.FindStringSubmatch(err.Error()); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return code
+	}
+
+	return 0
+}
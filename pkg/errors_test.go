@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/civo/civogo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCivoErrorToStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     codes.Code
+		reason   string
+		httpCode string
+	}{
+		{"typed error", fmt.Errorf("%w: not enough quota", civogo.QuotaLimitReachedError), codes.ResourceExhausted, "QuotaLimitReachedError", ""},
+		{"error matched by name", fmt.Errorf("%w: no such database", civogo.DatabaseInstanceNotFoundError), codes.NotFound, "DatabaseInstanceNotFoundError", ""},
+		{"HTTP error", civogo.HTTPError{Code: 429, Status: "429 Too Many Requests"}, codes.ResourceExhausted, "HTTPError", "429"},
+		{"undecoded error", errors.New("UnknownError: Error: code: 503, message: down"), codes.Unavailable, "UnknownError", "503"},
+		{"unknown error", errors.New("something broke"), codes.Unknown, "UnknownError", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := status.Convert(CivoErrorToStatus(test.err))
+			if s.Code() != test.code {
+				t.Fatalf("got %v, want %v", s.Code(), test.code)
+			}
+
+			if len(s.Details()) != 1 {
+				t.Fatalf("got the details %v, want an ErrorInfo", s.Details())
+			}
+			info := s.Details()[0].(*errdetails.ErrorInfo)
+			if info.Reason != test.reason || info.Domain != errorDomain || info.Metadata["http_status"] != test.httpCode {
+				t.Fatalf("got the ErrorInfo %v, want the reason %s and the HTTP status %q", info, test.reason, test.httpCode)
+			}
+		})
+	}
+}
+
+func TestCivoErrorToStatusKeepsStatusAndContextErrors(t *testing.T) {
+	if err := CivoErrorToStatus(nil); err != nil {
+		t.Fatalf("got %v for no error", err)
+	}
+
+	err := status.Error(codes.FailedPrecondition, "stopped")
+	if got := CivoErrorToStatus(err); status.Code(got) != codes.FailedPrecondition || status.Convert(got).Message() != "stopped" {
+		t.Fatalf("got %v, want the status unchanged", got)
+	}
+
+	if got := CivoErrorToStatus(fmt.Errorf("calling Civo: %w", context.DeadlineExceeded)); status.Code(got) != codes.DeadlineExceeded {
+		t.Fatalf("got %v, want DeadlineExceeded", got)
+	}
+}
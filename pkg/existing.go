@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// existingResource returns the resource of the items with the name and the namespace of the one to
+// create, so creating it again returns it instead of making a duplicate. It fails with AlreadyExists
+// when diff finds fields of the call that differ, and when the name is taken in another namespace as
+// Civo names are unique in the region. It returns nil when there is no such resource
+func existingResource[T any](kind string, in *metav1.ObjectMeta, items []T, diff func(T) specDiff) (T, error) {
+	var none T
+	for _, item := range items {
+		meta := objectMeta(item)
+		if meta == nil || meta.Name != in.Name {
+			continue
+		}
+
+		if meta.Namespace != in.Namespace {
+			return none, status.Errorf(codes.AlreadyExists, "%s %q already exists in namespace %q", kind, in.Name, meta.Namespace)
+		}
+
+		if fields := diff(item); len(fields) > 0 {
+			return none, status.Errorf(codes.AlreadyExists, "%s %q already exists in namespace %q with different %s", kind, in.Name, in.Namespace, strings.Join(fields, ", "))
+		}
+
+		return item, nil
+	}
+
+	return none, nil
+}
+
+// specDiff are the fields of a call that differ from the existing resource. Only the fields set in
+// the call are compared, the others are left to the defaults of the Civo API
+type specDiff []string
+
+// compare adds the field when it is set in the call and differs
+func (d *specDiff) compare(field, want, got string) {
+	if want != "" && want != got {
+		*d = append(*d, field)
+	}
+}
+
+// compareList adds the field when it is set in the call and the items differ
+func (d *specDiff) compareList(field string, want, got []string) {
+	if len(want) > 0 && strings.Join(want, ",") != strings.Join(got, ",") {
+		*d = append(*d, field)
+	}
+}
@@ -3,6 +3,7 @@ package pkg
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/civo/civogo"
 	"k8s.io/apimachinery/pkg/types"
@@ -13,7 +14,7 @@ import (
 
 func (s *Server) ListFirewall(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.FirewallList, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all the firewall rules again and return them
 	firewall, err := client.ListFirewalls()
@@ -109,7 +110,7 @@ func (s *Server) ListFirewall(ctx context.Context, option *opencpspec.FilterOpti
 
 func (s *Server) GetFirewall(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Firewall, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	fw, err := client.FindFirewall(*option.Name)
 	if err != nil {
@@ -126,7 +127,7 @@ func (s *Server) GetFirewall(ctx context.Context, option *opencpspec.FilterOptio
 
 		// filter the firewall by network
 		if fw.NetworkID != string(network.Metadata.UID) {
-			return nil, nil
+			return nil, notFoundError("firewall", fw.Name)
 		}
 
 		// set the network name
@@ -182,7 +183,11 @@ func (s *Server) GetFirewall(ctx context.Context, option *opencpspec.FilterOptio
 
 func (s *Server) CreateFirewall(ctx context.Context, in *opencpspec.Firewall) (*opencpspec.Firewall, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
+
+	if err := validateFirewall(in); err != nil {
+		return nil, err
+	}
 
 	// get the network
 	network, err := s.GetNamespace(ctx, &opencpspec.FilterOptions{Name: &in.Metadata.Namespace})
@@ -194,7 +199,7 @@ func (s *Server) CreateFirewall(ctx context.Context, in *opencpspec.Firewall) (*
 	createDefaultRules := false
 	fwConfig := &civogo.FirewallConfig{
 		Name:        in.Metadata.Name,
-		Region:      client.Region,
+		Region:      client.GetRegion(),
 		NetworkID:   string(network.Metadata.UID),
 		CreateRules: &createDefaultRules,
 	}
@@ -222,6 +227,20 @@ func (s *Server) CreateFirewall(ctx context.Context, in *opencpspec.Firewall) (*
 		})
 	}
 
+	// Return the firewall if it was already created
+	existing, err := s.existingFirewall(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	// Stop before creating anything in a dry run
+	if isDryRun(ctx) {
+		return dryRunFirewall(in, network.Metadata.Name, len(fwConfig.Rules)), nil
+	}
+
 	// create the firewall
 	fw, err := client.NewFirewall(fwConfig)
 	if err != nil {
@@ -233,7 +252,7 @@ func (s *Server) CreateFirewall(ctx context.Context, in *opencpspec.Firewall) (*
 }
 
 func (s *Server) DeleteFirewall(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Firewall, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the firewall
 	fw, err := s.GetFirewall(ctx, option)
@@ -241,6 +260,10 @@ func (s *Server) DeleteFirewall(ctx context.Context, option *opencpspec.FilterOp
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return fw, nil
+	}
+
 	// Delete the firewall
 	if fw != nil {
 		_, err = client.DeleteFirewall(string(fw.Metadata.UID))
@@ -251,3 +274,78 @@ func (s *Server) DeleteFirewall(ctx context.Context, option *opencpspec.FilterOp
 
 	return fw, nil
 }
+
+// validateFirewall checks the fields and the rules of a firewall to create
+func validateFirewall(in *opencpspec.Firewall) error {
+	if err := validateObjectMeta("firewall", in.Metadata); err != nil {
+		return err
+	}
+	if in.Spec == nil {
+		return invalidArgument("the spec of firewall %q is required", in.Metadata.Name)
+	}
+
+	if err := validateFirewallRules("ingress", in.Spec.Ingress); err != nil {
+		return err
+	}
+
+	return validateFirewallRules("egress", in.Spec.Egress)
+}
+
+// existingFirewall returns the firewall with the name of in in its namespace, nil if there is none,
+// or AlreadyExists if it does not match in
+func (s *Server) existingFirewall(ctx context.Context, in *opencpspec.Firewall) (*opencpspec.Firewall, error) {
+	firewalls, err := s.ListFirewall(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingResource("firewall", in.Metadata, firewalls.Items, func(fw *opencpspec.Firewall) specDiff {
+		diff := specDiff{}
+		if !sameFirewallRules(in.Spec.Ingress, fw.Spec.Ingress) {
+			diff = append(diff, "ingress")
+		}
+		if !sameFirewallRules(in.Spec.Egress, fw.Spec.Egress) {
+			diff = append(diff, "egress")
+		}
+		return diff
+	})
+}
+
+// sameFirewallRules returns true if the rules are the same, comparing the fields set in want
+func sameFirewallRules(want, got []*opencpspec.FirewallRules) bool {
+	if len(want) != len(got) {
+		return false
+	}
+
+	for i := range want {
+		diff := specDiff{}
+		diff.compare("protocol", strings.ToLower(want[i].Protocol), strings.ToLower(got[i].Protocol))
+		diff.compare("ports", want[i].Ports, got[i].Ports)
+		diff.compare("action", strings.ToLower(want[i].Action), strings.ToLower(got[i].Action))
+		diff.compare("label", want[i].Label, got[i].Label)
+		diff.compareList("source", want[i].Source, got[i].Source)
+		if len(diff) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dryRunFirewall returns the firewall that would be created
+func dryRunFirewall(in *opencpspec.Firewall, namespace string, rules int) *opencpspec.Firewall {
+	return &opencpspec.Firewall{
+		Metadata: &metav1.ObjectMeta{
+			Name:      in.Metadata.Name,
+			Namespace: namespace,
+		},
+		Spec: &opencpspec.FirewallSpec{
+			Ingress: append([]*opencpspec.FirewallRules{}, in.Spec.Ingress...),
+			Egress:  append([]*opencpspec.FirewallRules{}, in.Spec.Egress...),
+		},
+		Status: &opencpspec.FirewallStatus{
+			State:      dryRunState,
+			TotalRules: strconv.Itoa(rules),
+		},
+	}
+}
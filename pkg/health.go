@@ -0,0 +1,178 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Health reports whether the server can serve calls, through the grpc.health.v1 service
+// with one status per OpenCP service, and through the HTTP /healthz and /readyz endpoints
+type Health struct {
+	server   *health.Server
+	services []string
+	apiURL   string
+	client   *http.Client
+	mode     string
+
+	mu           sync.Mutex
+	serving      bool
+	reason       string
+	shuttingDown bool
+}
+
+// healthServer is the health service, it can be called without a Civo API token
+type healthServer struct {
+	*health.Server
+}
+
+// AuthFuncOverride lets the health checks skip the auth middleware
+func (s *healthServer) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	return ctx, nil
+}
+
+// NewHealth creates the health of the given gRPC services. The Civo API at apiURL is probed to know
+// if the server can serve calls, an empty apiURL means there is nothing to probe. The mode of the server,
+// read-write or read-only, is reported by the HTTP endpoints
+func NewHealth(services []string, apiURL string, timeout time.Duration, mode string) *Health {
+	h := &Health{
+		server:   health.NewServer(),
+		services: services,
+		apiURL:   apiURL,
+		client:   &http.Client{Timeout: timeout},
+		mode:     mode,
+	}
+	h.setServing(true, "")
+
+	return h
+}
+
+// Register adds the health service to the gRPC server
+func (h *Health) Register(r grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(r, &healthServer{h.server})
+}
+
+// Run probes the Civo API every interval until the context is done
+func (h *Health) Run(ctx context.Context, interval time.Duration) {
+	if h.apiURL == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := h.Probe(ctx); err != nil {
+			h.setServing(false, err.Error())
+		} else {
+			h.setServing(true, "")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe checks the Civo API answers. Any answer that is not a server error counts,
+// the probe has no token so the API refuses it
+func (h *Health) Probe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(h.apiURL, "/")+"/v2/regions", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("the Civo API is unreachable: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("the Civo API answered %s", resp.Status)
+	}
+
+	return nil
+}
+
+// setServing sets the status of the server and of every service
+func (h *Health) setServing(serving bool, reason string) {
+	h.mu.Lock()
+	if h.shuttingDown {
+		h.mu.Unlock()
+		return
+	}
+	changed := h.serving != serving
+	h.serving = serving
+	h.reason = reason
+	h.mu.Unlock()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if !serving {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	// The empty service is the status of the whole server
+	h.server.SetServingStatus("", status)
+	for _, service := range h.services {
+		h.server.SetServingStatus(service, status)
+	}
+
+	if changed && !serving {
+		logrus.WithField("reason", reason).Warn("the server is not serving")
+	} else if changed {
+		logrus.Info("the server is serving")
+	}
+}
+
+// Shutdown sets every status to NOT_SERVING for good, so the clients and load balancers
+// stop sending calls to a server that is stopping
+func (h *Health) Shutdown() {
+	h.mu.Lock()
+	h.serving = false
+	h.reason = "the server is shutting down"
+	h.shuttingDown = true
+	h.mu.Unlock()
+
+	h.server.Shutdown()
+}
+
+// Serving returns whether the server can serve calls, and why not when it can't
+func (h *Health) Serving() (bool, string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.serving, h.reason
+}
+
+// RegisterHTTP adds /healthz, which only checks the process is up, and /readyz,
+// which fails when the server is not serving, to an HTTP mux. Both give the mode of the
+// server in the X-OpenCP-Mode header and on the second line of the body
+func (h *Health) RegisterHTTP(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OpenCP-Mode", h.mode)
+		fmt.Fprintf(w, "ok\nmode: %s\n", h.mode)
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OpenCP-Mode", h.mode)
+		serving, reason := h.Serving()
+		if !serving {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "not serving: %s\nmode: %s\n", reason, h.mode)
+			return
+		}
+
+		fmt.Fprintf(w, "ok\nmode: %s\n", h.mode)
+	})
+}
@@ -0,0 +1,234 @@
+package pkg
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IdempotencyKeyMetadataKey is the metadata key of the idempotency key of a call, a call retried with
+	// the same key gets the answer of the first one instead of being run again
+	IdempotencyKeyMetadataKey = "x-opencp-idempotency-key"
+
+	// IdempotentReplayMetadataKey is the response header set to true when the answer is the one of an earlier call
+	IdempotentReplayMetadataKey = "x-opencp-idempotent-replay"
+)
+
+// maxIdempotencyKeyLength is the longest idempotency key accepted
+const maxIdempotencyKeyLength = 255
+
+var (
+	idempotencyReplays = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "opencp_idempotency_replays_total",
+		Help: "Number of calls answered with the remembered answer of an earlier call with the same idempotency key.",
+	}, []string{"grpc_service", "grpc_method"})
+	idempotencyKeys = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "opencp_idempotency_keys",
+		Help: "Number of idempotency keys remembered or running.",
+	})
+)
+
+// rememberedCodes are the outcomes a call would have again if it was retried, the others are forgotten
+// so the retry runs the call
+var rememberedCodes = map[codes.Code]bool{
+	codes.OK:                 true,
+	codes.InvalidArgument:    true,
+	codes.NotFound:           true,
+	codes.AlreadyExists:      true,
+	codes.PermissionDenied:   true,
+	codes.FailedPrecondition: true,
+	codes.OutOfRange:         true,
+	codes.Unimplemented:      true,
+}
+
+// IdempotencyConfig is how long the answers of the calls with an idempotency key are remembered
+type IdempotencyConfig struct {
+	// Window is how long an answer is remembered, zero turns the idempotency keys off
+	Window Duration `yaml:"window"`
+
+	// MaxKeys is the most keys remembered, the oldest are forgotten first
+	MaxKeys int `yaml:"maxKeys"`
+}
+
+// validate returns the problems of the idempotency settings
+func (c *IdempotencyConfig) validate() []string {
+	problems := []string{}
+
+	if c.Window.Duration < 0 {
+		problems = append(problems, "the idempotency window can't be negative")
+	}
+	if c.MaxKeys < 1 {
+		problems = append(problems, fmt.Sprintf("the idempotency max keys %d must be at least 1", c.MaxKeys))
+	}
+
+	return problems
+}
+
+// IdempotencyCache remembers the answers of the calls changing resources that have an idempotency key,
+// for each caller. A call with a key already used gets the answer of the first call, or waits for it
+// while the first call is running
+type IdempotencyCache struct {
+	config IdempotencyConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// idempotencyEntry is a call with an idempotency key, running until done is closed
+type idempotencyEntry struct {
+	key         string
+	requestHash string
+	done        chan struct{}
+
+	// Set when done is closed, remembered is false when the call has to be run again
+	remembered bool
+	resp       interface{}
+	err        error
+	expires    time.Time
+}
+
+// NewIdempotencyCache creates an empty IdempotencyCache
+func NewIdempotencyCache(config IdempotencyConfig) *IdempotencyCache {
+	return &IdempotencyCache{
+		config:  config,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// UnaryServerInterceptor answers the calls with an idempotency key already used with the remembered answer
+func (c *IdempotencyCache) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		service, method := splitMethod(info.FullMethod)
+		idempotencyKey := metadataValue(ctx, IdempotencyKeyMetadataKey)
+		if c.config.Window.Duration <= 0 || idempotencyKey == "" || !isMutatingMethod(method) {
+			return handler(ctx, req)
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			return nil, invalidArgument("the idempotency key can't be longer than %d characters", maxIdempotencyKeyLength)
+		}
+
+		key := idempotencyScope(ctx, info.FullMethod) + " " + idempotencyKey
+		requestHash := hashRequest(req)
+
+		for {
+			entry, running, err := c.start(key, requestHash)
+			if err != nil {
+				return nil, err
+			}
+
+			if !running {
+				resp, err := handler(ctx, req)
+				c.finish(entry, resp, err)
+				return resp, err
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			case <-entry.done:
+			}
+
+			if entry.remembered {
+				idempotencyReplays.WithLabelValues(service, method).Inc()
+				grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayMetadataKey, "true"))
+				return cloneMessage(entry.resp), entry.err
+			}
+		}
+	}
+}
+
+// start returns the entry of the key, and true if it is an earlier call, or a new entry for the call to run
+func (c *IdempotencyCache) start(key, requestHash string) (*idempotencyEntry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*idempotencyEntry)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			if entry.requestHash != requestHash {
+				return nil, false, invalidArgument("the idempotency key was already used by a different call")
+			}
+			return entry, true, nil
+		}
+		c.remove(elem)
+	}
+
+	entry := &idempotencyEntry{key: key, requestHash: requestHash, done: make(chan struct{})}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.MaxKeys {
+		c.remove(c.lru.Back())
+	}
+	idempotencyKeys.Set(float64(len(c.entries)))
+
+	return entry, false, nil
+}
+
+// finish remembers the answer of the call, or forgets the key when the call can be run again,
+// and wakes up the calls waiting for it
+func (c *IdempotencyCache) finish(entry *idempotencyEntry, resp interface{}, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rememberedCodes[status.Code(CivoErrorToStatus(err))] {
+		entry.remembered = true
+		entry.resp = cloneMessage(resp)
+		entry.err = CivoErrorToStatus(err)
+		entry.expires = time.Now().Add(c.config.Window.Duration)
+	} else if elem, ok := c.entries[entry.key]; ok && elem.Value == entry {
+		c.remove(elem)
+	}
+	close(entry.done)
+}
+
+// remove forgets an entry. It must be called with the lock held
+func (c *IdempotencyCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*idempotencyEntry).key)
+	idempotencyKeys.Set(float64(len(c.entries)))
+}
+
+// idempotencyScope returns what the calls sharing an idempotency key must have in common to share
+// an answer: the caller, the method, the regions it runs in and whether it is a dry run, so a dry run
+// or a call to another region is never answered with the answer of another
+func idempotencyScope(ctx context.Context, fullMethod string) string {
+	region := strings.Join(requestedRegions(ctx), ",")
+	if client, ok := ctx.Value("client").(Provider); ok && region == "" {
+		region = client.GetRegion()
+	}
+
+	return strings.Join([]string{CallerTokenHash(ctx), fullMethod, region, strconv.FormatBool(isDryRun(ctx))}, " ")
+}
+
+// hashRequest returns the SHA-256 hash of the body of a call
+func hashRequest(req interface{}) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cloneMessage returns a deep copy of an answer, so each caller gets its own to mask the secrets of
+func cloneMessage(msg interface{}) interface{} {
+	if msg == nil {
+		return nil
+	}
+
+	return proto.Clone(msg.(proto.Message))
+}
@@ -0,0 +1,66 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	civov1 "github.com/civo/civo-opencp/api/civo/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// idempotentCall returns the context of a call with the idempotency key, in the region, with the extra metadata
+func idempotentCall(t *testing.T, region string, pairs ...string) context.Context {
+	t.Helper()
+
+	client, err := NewMemoryBackend().Provider("idempotency-token", region)
+	if err != nil {
+		t.Fatalf("creating the memory provider: %v", err)
+	}
+
+	md := metadata.Pairs(append([]string{"authorization", "bearer idempotency-token", IdempotencyKeyMetadataKey, "key-1"}, pairs...)...)
+	return context.WithValue(metadata.NewIncomingContext(context.Background(), md), "client", client)
+}
+
+func TestIdempotencyCacheScope(t *testing.T) {
+	cache := NewIdempotencyCache(IdempotencyConfig{Window: Duration{Duration: time.Minute}, MaxKeys: 16})
+	interceptor := cache.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/civo.v1.PowerService/StopVirtualMachine"}
+	req := &civov1.ListDiskImageRequest{Distribution: "ubuntu"}
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return &civov1.DiskImage{Name: fmt.Sprintf("call %d", calls)}, nil
+	}
+
+	steps := []struct {
+		name  string
+		ctx   context.Context
+		calls int
+	}{
+		{"dry run", idempotentCall(t, "LON1", DryRunMetadataKey, "true"), 1},
+		{"real call after the dry run", idempotentCall(t, "LON1"), 2},
+		{"retried real call", idempotentCall(t, "LON1"), 2},
+		{"retried dry run", idempotentCall(t, "LON1", DryRunMetadataKey, "true"), 2},
+		{"other region", idempotentCall(t, "NYC1"), 3},
+		{"other requested region", idempotentCall(t, "LON1", RegionMetadataKey, "FRA1"), 4},
+	}
+	answers := map[string]string{}
+	for _, step := range steps {
+		resp, err := interceptor(step.ctx, req, info, handler)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if calls != step.calls {
+			t.Fatalf("%s: the handler ran %d times, want %d", step.name, calls, step.calls)
+		}
+		answers[step.name] = resp.(*civov1.DiskImage).Name
+	}
+
+	if answers["retried real call"] != answers["real call after the dry run"] || answers["retried dry run"] != answers["dry run"] {
+		t.Fatalf("retries got %v, want the answers of the first calls", answers)
+	}
+}
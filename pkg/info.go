@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"context"
+	"runtime/debug"
+
+	civov1 "github.com/civo/civo-opencp/api/civo/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// Commit and BuildDate are set at build time with -ldflags like Version,
+// when they are not the VCS information Go adds to the binary is used
+var (
+	Commit    = ""
+	BuildDate = ""
+)
+
+// opencpSpecModule is the module of the OpenCP specification implemented by the server
+const opencpSpecModule = "github.com/opencontrolplane/opencp-spec"
+
+// InfoServer serves the InfoService, it can be called without a Civo API token
+type InfoServer struct {
+	civov1.UnimplementedInfoServiceServer
+
+	services      []string
+	defaultRegion string
+	readOnly      *ReadOnly
+}
+
+// NewInfoServer creates the InfoService of a server serving the given gRPC services
+func NewInfoServer(services []string, defaultRegion string, readOnly *ReadOnly) *InfoServer {
+	return &InfoServer{
+		services:      services,
+		defaultRegion: defaultRegion,
+		readOnly:      readOnly,
+	}
+}
+
+// Register adds the InfoService to the gRPC server
+func (s *InfoServer) Register(r grpc.ServiceRegistrar) {
+	civov1.RegisterInfoServiceServer(r, s)
+}
+
+// GetInfo returns the build and configuration of the server
+func (s *InfoServer) GetInfo(ctx context.Context, in *civov1.GetInfoRequest) (*civov1.Info, error) {
+	info := &civov1.Info{
+		Version:       Version,
+		Commit:        Commit,
+		BuildDate:     BuildDate,
+		Services:      s.services,
+		DefaultRegion: s.defaultRegion,
+		Mode:          s.readOnly.Mode(ctx),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info, nil
+	}
+
+	for _, setting := range build.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && info.Commit == "":
+			info.Commit = setting.Value
+		case setting.Key == "vcs.time" && info.BuildDate == "":
+			info.BuildDate = setting.Value
+		}
+	}
+
+	for _, dep := range build.Deps {
+		if dep.Path == opencpSpecModule {
+			info.OpencpSpecVersion = dep.Version
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				info.OpencpSpecVersion = dep.Replace.Version
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// AuthFuncOverride lets the InfoService skip the auth middleware
+func (s *InfoServer) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	return ctx, nil
+}
+
+// reflectionServer is the server reflection service, it can be called without a Civo API token
+type reflectionServer struct {
+	rpb.ServerReflectionServer
+}
+
+// AuthFuncOverride lets the server reflection skip the auth middleware
+func (s *reflectionServer) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	return ctx, nil
+}
+
+// RegisterReflection adds the server reflection service to the gRPC server, so tools like grpcurl
+// can list and call its services
+func RegisterReflection(s *grpc.Server) {
+	rpb.RegisterServerReflectionServer(s, &reflectionServer{
+		reflection.NewServer(reflection.ServerOptions{Services: s}),
+	})
+}
@@ -0,0 +1,495 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/civo/civogo"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+)
+
+// ProviderCallHook runs around every Civo API call made by the provider of a gRPC call,
+// it must call next and return its error. The context is the one of the gRPC call
+type ProviderCallHook func(ctx context.Context, endpoint string, next func() error) error
+
+// instrumentedProvider is a provider running hooks around every Civo API call. civogo replaces
+// its HTTP transport on every request, so the calls are instrumented here and not on the transport
+type instrumentedProvider struct {
+	Provider
+	ctx   context.Context
+	hooks []ProviderCallHook
+}
+
+// ProviderUnaryServerInterceptor gives the handlers a provider running the hooks around every Civo API call,
+// the first hook is the outermost, and remembering the lookups of the call
+func ProviderUnaryServerInterceptor(hooks ...ProviderCallHook) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(cacheLookups(instrumentProvider(ctx, hooks)), req)
+	}
+}
+
+// ProviderStreamServerInterceptor is the ProviderUnaryServerInterceptor of the stream calls
+func ProviderStreamServerInterceptor(hooks ...ProviderCallHook) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = cacheLookups(instrumentProvider(stream.Context(), hooks))
+		return handler(srv, wrapped)
+	}
+}
+
+// instrumentProvider replaces the provider of the context with one running the hooks
+func instrumentProvider(ctx context.Context, hooks []ProviderCallHook) context.Context {
+	client, ok := ctx.Value("client").(Provider)
+	if !ok || len(hooks) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, "client", &instrumentedProvider{Provider: client, ctx: ctx, hooks: hooks})
+}
+
+// call runs a Civo API call through the hooks
+func (p *instrumentedProvider) call(endpoint string, fn func() error) error {
+	next := fn
+	for i := len(p.hooks) - 1; i >= 0; i-- {
+		hook, inner := p.hooks[i], next
+		next = func() error {
+			return hook(p.ctx, endpoint, inner)
+		}
+	}
+
+	return next()
+}
+
+// The Provider calls going to the Civo API
+
+func (p *instrumentedProvider) ListRegions() (result []civogo.Region, err error) {
+	err = p.call("ListRegions", func() error {
+		result, err = p.Provider.ListRegions()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListAllInstances() (result []civogo.Instance, err error) {
+	err = p.call("ListAllInstances", func() error {
+		result, err = p.Provider.ListAllInstances()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindInstance(search string) (result *civogo.Instance, err error) {
+	err = p.call("FindInstance", func() error {
+		result, err = p.Provider.FindInstance(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) CreateInstance(config *civogo.InstanceConfig) (result *civogo.Instance, err error) {
+	err = p.call("CreateInstance", func() error {
+		result, err = p.Provider.CreateInstance(config)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteInstance(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteInstance", func() error {
+		result, err = p.Provider.DeleteInstance(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) UpgradeInstance(id, newSize string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("UpgradeInstance", func() error {
+		result, err = p.Provider.UpgradeInstance(id, newSize)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) SetInstanceTags(i *civogo.Instance, tags string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("SetInstanceTags", func() error {
+		result, err = p.Provider.SetInstanceTags(i, tags)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) SetInstanceFirewall(id, firewallID string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("SetInstanceFirewall", func() error {
+		result, err = p.Provider.SetInstanceFirewall(id, firewallID)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) RebuildInstance(id, templateID string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("RebuildInstance", func() error {
+		result, err = p.Provider.RebuildInstance(id, templateID)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) StartInstance(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("StartInstance", func() error {
+		result, err = p.Provider.StartInstance(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) StopInstance(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("StopInstance", func() error {
+		result, err = p.Provider.StopInstance(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) SoftRebootInstance(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("SoftRebootInstance", func() error {
+		result, err = p.Provider.SoftRebootInstance(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) HardRebootInstance(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("HardRebootInstance", func() error {
+		result, err = p.Provider.HardRebootInstance(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListDiskImages() (result []civogo.DiskImage, err error) {
+	err = p.call("ListDiskImages", func() error {
+		result, err = p.Provider.ListDiskImages()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListInstanceSizes() (result []civogo.InstanceSize, err error) {
+	err = p.call("ListInstanceSizes", func() error {
+		result, err = p.Provider.ListInstanceSizes()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListNetworks() (result []civogo.Network, err error) {
+	err = p.call("ListNetworks", func() error {
+		result, err = p.Provider.ListNetworks()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindNetwork(search string) (result *civogo.Network, err error) {
+	err = p.call("FindNetwork", func() error {
+		result, err = p.Provider.FindNetwork(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewNetwork(label string) (result *civogo.NetworkResult, err error) {
+	err = p.call("NewNetwork", func() error {
+		result, err = p.Provider.NewNetwork(label)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteNetwork(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteNetwork", func() error {
+		result, err = p.Provider.DeleteNetwork(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListFirewalls() (result []civogo.Firewall, err error) {
+	err = p.call("ListFirewalls", func() error {
+		result, err = p.Provider.ListFirewalls()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindFirewall(search string) (result *civogo.Firewall, err error) {
+	err = p.call("FindFirewall", func() error {
+		result, err = p.Provider.FindFirewall(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewFirewall(config *civogo.FirewallConfig) (result *civogo.FirewallResult, err error) {
+	err = p.call("NewFirewall", func() error {
+		result, err = p.Provider.NewFirewall(config)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteFirewall(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteFirewall", func() error {
+		result, err = p.Provider.DeleteFirewall(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListDNSDomains() (result []civogo.DNSDomain, err error) {
+	err = p.call("ListDNSDomains", func() error {
+		result, err = p.Provider.ListDNSDomains()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindDNSDomain(search string) (result *civogo.DNSDomain, err error) {
+	err = p.call("FindDNSDomain", func() error {
+		result, err = p.Provider.FindDNSDomain(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) CreateDNSDomain(name string) (result *civogo.DNSDomain, err error) {
+	err = p.call("CreateDNSDomain", func() error {
+		result, err = p.Provider.CreateDNSDomain(name)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteDNSDomain(d *civogo.DNSDomain) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteDNSDomain", func() error {
+		result, err = p.Provider.DeleteDNSDomain(d)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListDNSRecords(dnsDomainID string) (result []civogo.DNSRecord, err error) {
+	err = p.call("ListDNSRecords", func() error {
+		result, err = p.Provider.ListDNSRecords(dnsDomainID)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) CreateDNSRecord(domainID string, r *civogo.DNSRecordConfig) (result *civogo.DNSRecord, err error) {
+	err = p.call("CreateDNSRecord", func() error {
+		result, err = p.Provider.CreateDNSRecord(domainID, r)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListSSHKeys() (result []civogo.SSHKey, err error) {
+	err = p.call("ListSSHKeys", func() error {
+		result, err = p.Provider.ListSSHKeys()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindSSHKey(search string) (result *civogo.SSHKey, err error) {
+	err = p.call("FindSSHKey", func() error {
+		result, err = p.Provider.FindSSHKey(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewSSHKey(name string, publicKey string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("NewSSHKey", func() error {
+		result, err = p.Provider.NewSSHKey(name, publicKey)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteSSHKey(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteSSHKey", func() error {
+		result, err = p.Provider.DeleteSSHKey(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListIPs() (result *civogo.PaginatedIPs, err error) {
+	err = p.call("ListIPs", func() error {
+		result, err = p.Provider.ListIPs()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindIP(search string) (result *civogo.IP, err error) {
+	err = p.call("FindIP", func() error {
+		result, err = p.Provider.FindIP(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewIP(v *civogo.CreateIPRequest) (result *civogo.IP, err error) {
+	err = p.call("NewIP", func() error {
+		result, err = p.Provider.NewIP(v)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteIP(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteIP", func() error {
+		result, err = p.Provider.DeleteIP(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListDatabases() (result *civogo.PaginatedDatabases, err error) {
+	err = p.call("ListDatabases", func() error {
+		result, err = p.Provider.ListDatabases()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindDatabase(search string) (result *civogo.Database, err error) {
+	err = p.call("FindDatabase", func() error {
+		result, err = p.Provider.FindDatabase(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewDatabase(v *civogo.CreateDatabaseRequest) (result *civogo.Database, err error) {
+	err = p.call("NewDatabase", func() error {
+		result, err = p.Provider.NewDatabase(v)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteDatabase(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteDatabase", func() error {
+		result, err = p.Provider.DeleteDatabase(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListKubernetesClusters() (result *civogo.PaginatedKubernetesClusters, err error) {
+	err = p.call("ListKubernetesClusters", func() error {
+		result, err = p.Provider.ListKubernetesClusters()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindKubernetesCluster(search string) (result *civogo.KubernetesCluster, err error) {
+	err = p.call("FindKubernetesCluster", func() error {
+		result, err = p.Provider.FindKubernetesCluster(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewKubernetesClusters(kc *civogo.KubernetesClusterConfig) (result *civogo.KubernetesCluster, err error) {
+	err = p.call("NewKubernetesClusters", func() error {
+		result, err = p.Provider.NewKubernetesClusters(kc)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListAvailableKubernetesVersions() (result []civogo.KubernetesVersion, err error) {
+	err = p.call("ListAvailableKubernetesVersions", func() error {
+		result, err = p.Provider.ListAvailableKubernetesVersions()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteKubernetesCluster(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteKubernetesCluster", func() error {
+		result, err = p.Provider.DeleteKubernetesCluster(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListObjectStores() (result *civogo.PaginatedObjectstores, err error) {
+	err = p.call("ListObjectStores", func() error {
+		result, err = p.Provider.ListObjectStores()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindObjectStore(search string) (result *civogo.ObjectStore, err error) {
+	err = p.call("FindObjectStore", func() error {
+		result, err = p.Provider.FindObjectStore(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewObjectStore(v *civogo.CreateObjectStoreRequest) (result *civogo.ObjectStore, err error) {
+	err = p.call("NewObjectStore", func() error {
+		result, err = p.Provider.NewObjectStore(v)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteObjectStore(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteObjectStore", func() error {
+		result, err = p.Provider.DeleteObjectStore(id)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) ListObjectStoreCredentials() (result *civogo.PaginatedObjectStoreCredentials, err error) {
+	err = p.call("ListObjectStoreCredentials", func() error {
+		result, err = p.Provider.ListObjectStoreCredentials()
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) FindObjectStoreCredential(search string) (result *civogo.ObjectStoreCredential, err error) {
+	err = p.call("FindObjectStoreCredential", func() error {
+		result, err = p.Provider.FindObjectStoreCredential(search)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) NewObjectStoreCredential(v *civogo.CreateObjectStoreCredentialRequest) (result *civogo.ObjectStoreCredential, err error) {
+	err = p.call("NewObjectStoreCredential", func() error {
+		result, err = p.Provider.NewObjectStoreCredential(v)
+		return err
+	})
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteObjectStoreCredential(id string) (result *civogo.SimpleResponse, err error) {
+	err = p.call("DeleteObjectStoreCredential", func() error {
+		result, err = p.Provider.DeleteObjectStoreCredential(id)
+		return err
+	})
+	return result, err
+}
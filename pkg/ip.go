@@ -12,7 +12,7 @@ import (
 
 func (s *Server) ListIp(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.IpList, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all IPs and return them
 	allIps, err := client.ListIPs()
@@ -49,12 +49,12 @@ func (s *Server) ListIp(ctx context.Context, option *opencpspec.FilterOptions) (
 
 func (s *Server) CreateIp(ctx context.Context, in *opencpspec.Ip) (*opencpspec.Ip, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Create the IP request
 	ipRequest := &civogo.CreateIPRequest{
 		Name:   in.Spec.Name,
-		Region: client.Region,
+		Region: client.GetRegion(),
 	}
 
 	// Create the IP
@@ -69,7 +69,7 @@ func (s *Server) CreateIp(ctx context.Context, in *opencpspec.Ip) (*opencpspec.I
 
 func (s *Server) DeleteIp(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Ip, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the IP
 	ip, err := s.GetIp(ctx, option)
@@ -77,6 +77,10 @@ func (s *Server) DeleteIp(ctx context.Context, option *opencpspec.FilterOptions)
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return ip, nil
+	}
+
 	// Delete the IP
 	_, err = client.DeleteIP(string(ip.Metadata.UID))
 	if err != nil {
@@ -88,7 +92,7 @@ func (s *Server) DeleteIp(ctx context.Context, option *opencpspec.FilterOptions)
 
 func (s *Server) GetIp(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Ip, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the IP
 	ip, err := client.FindIP(*option.Name)
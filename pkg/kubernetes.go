@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"context"
+	"strings"
 
 	"github.com/civo/civogo"
 	"k8s.io/apimachinery/pkg/types"
@@ -11,7 +12,11 @@ import (
 )
 
 func (s *Server) CreateKubernetesCluster(ctx context.Context, in *opencpspec.KubernetesCluster) (*opencpspec.KubernetesCluster, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
+
+	if err := validateKubernetesCluster(in); err != nil {
+		return nil, err
+	}
 
 	// get the network
 	network, err := s.GetNamespace(ctx, &opencpspec.FilterOptions{Name: &in.Metadata.Namespace})
@@ -32,7 +37,7 @@ func (s *Server) CreateKubernetesCluster(ctx context.Context, in *opencpspec.Kub
 	// Create a kubernetes cluster config
 	k8sConfig := &civogo.KubernetesClusterConfig{
 		Name:              in.Metadata.Name,
-		Region:            client.Region,
+		Region:            client.GetRegion(),
 		KubernetesVersion: in.Spec.Version,
 		NetworkID:         string(network.Metadata.UID),
 		Pools:             pools,
@@ -53,6 +58,20 @@ func (s *Server) CreateKubernetesCluster(ctx context.Context, in *opencpspec.Kub
 		k8sConfig.InstanceFirewall = string(firewall.Metadata.UID)
 	}
 
+	// Return the cluster if it was already created
+	existing, err := s.existingKubernetesCluster(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	// Stop before creating anything in a dry run
+	if isDryRun(ctx) {
+		return dryRunKubernetesCluster(client, in, network.Metadata.Name)
+	}
+
 	// create the kubernetes cluster
 	kubernetesCluster, err := client.NewKubernetesClusters(k8sConfig)
 	if err != nil {
@@ -69,7 +88,7 @@ func (s *Server) CreateKubernetesCluster(ctx context.Context, in *opencpspec.Kub
 }
 
 func (s *Server) GetKubernetesCluster(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.KubernetesCluster, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// check the options to see wish value to use
 	// TODO do a better check, put this in a util function
@@ -96,7 +115,7 @@ func (s *Server) GetKubernetesCluster(ctx context.Context, option *opencpspec.Fi
 
 		// filter the virtual machine by namespace
 		if k8s.NetworkID != string(network.Metadata.UID) {
-			return nil, nil
+			return nil, notFoundError("kubernetes cluster", k8s.Name)
 		}
 
 		// set the network name
@@ -143,7 +162,7 @@ func (s *Server) GetKubernetesCluster(ctx context.Context, option *opencpspec.Fi
 }
 
 func (s *Server) ListKubernetesCluster(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.KubernetesClusterList, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all the kubernetes clusters
 	allk8s, err := client.ListKubernetesClusters()
@@ -239,7 +258,7 @@ func (s *Server) ListKubernetesCluster(ctx context.Context, option *opencpspec.F
 }
 
 func (s *Server) DeleteKubernetesCluster(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.KubernetesCluster, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the kubernetes cluster
 	k8s, err := s.GetKubernetesCluster(ctx, option)
@@ -247,6 +266,10 @@ func (s *Server) DeleteKubernetesCluster(ctx context.Context, option *opencpspec
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return k8s, nil
+	}
+
 	// Delete the kubernetes cluster
 	if k8s != nil {
 		_, err = client.DeleteKubernetesCluster(string(k8s.Metadata.UID))
@@ -257,3 +280,102 @@ func (s *Server) DeleteKubernetesCluster(ctx context.Context, option *opencpspec
 
 	return k8s, nil
 }
+
+// validateKubernetesCluster checks the fields a kubernetes cluster needs to be created
+func validateKubernetesCluster(in *opencpspec.KubernetesCluster) error {
+	if err := validateObjectMeta("kubernetes cluster", in.Metadata); err != nil {
+		return err
+	}
+	if in.Spec == nil || len(in.Spec.Pools) == 0 {
+		return invalidArgument("kubernetes cluster %q needs at least one pool", in.Metadata.Name)
+	}
+
+	for i, pool := range in.Spec.Pools {
+		if pool == nil || pool.Size == "" {
+			return invalidArgument("the size of pool %d of kubernetes cluster %q is required", i, in.Metadata.Name)
+		}
+		if pool.Count < 1 {
+			return invalidArgument("pool %d of kubernetes cluster %q needs at least one node", i, in.Metadata.Name)
+		}
+	}
+
+	return nil
+}
+
+// existingKubernetesCluster returns the cluster with the name of in in its namespace, nil if there is none,
+// or AlreadyExists if it does not match in
+func (s *Server) existingKubernetesCluster(ctx context.Context, in *opencpspec.KubernetesCluster) (*opencpspec.KubernetesCluster, error) {
+	clusters, err := s.ListKubernetesCluster(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingResource("kubernetes cluster", in.Metadata, clusters.Items, func(k8s *opencpspec.KubernetesCluster) specDiff {
+		diff := specDiff{}
+		if in.Spec.Version != "" && !sameKubernetesVersion(in.Spec.Version, k8s.Spec.Version) {
+			diff = append(diff, "version")
+		}
+		diff.compare("firewall", in.Spec.Firewall, k8s.Spec.Firewall)
+		diff.compare("cniPlugin", in.Spec.CniPlugin, k8s.Spec.CniPlugin)
+		diff.compare("clusterType", in.Spec.ClusterType, k8s.Spec.ClusterType)
+		if !samePools(in.Spec.Pools, k8s.Spec.Pools) {
+			diff = append(diff, "pools")
+		}
+		return diff
+	})
+}
+
+// sameKubernetesVersion returns true if the version Civo reports for a cluster is the version asked for it,
+// which can be a label such as v1.26.4-k3s1 or a shorter version such as 1.26 or 1.26.4
+func sameKubernetesVersion(want, got string) bool {
+	want, got = strings.TrimPrefix(want, "v"), strings.TrimPrefix(got, "v")
+
+	return got == want || strings.HasPrefix(got, want+".") || strings.HasPrefix(got, want+"-")
+}
+
+// samePools returns true if the pools have the same sizes and node counts, and the same IDs when they are set
+func samePools(want, got []*opencpspec.KubernetesClusterPool) bool {
+	if len(want) != len(got) {
+		return false
+	}
+
+	for i := range want {
+		if want[i].Size != got[i].Size || want[i].Count != got[i].Count || (want[i].Id != "" && want[i].Id != got[i].Id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dryRunKubernetesCluster checks what the Civo API would refuse and returns the cluster that would be created
+func dryRunKubernetesCluster(client Provider, in *opencpspec.KubernetesCluster, namespace string) (*opencpspec.KubernetesCluster, error) {
+	if err := validateKubernetesVersion(client, in.Spec.Version); err != nil {
+		return nil, err
+	}
+
+	pools := []*opencpspec.KubernetesClusterPool{}
+	for _, pool := range in.Spec.Pools {
+		if err := validateSize(client, "kubernetes pool", pool.Size); err != nil {
+			return nil, err
+		}
+		pools = append(pools, &opencpspec.KubernetesClusterPool{Id: pool.Id, Size: pool.Size, Count: pool.Count})
+	}
+
+	return &opencpspec.KubernetesCluster{
+		Metadata: &metav1.ObjectMeta{
+			Name:      in.Metadata.Name,
+			Namespace: namespace,
+		},
+		Spec: &opencpspec.KubernetesClusterSpec{
+			Pools:       pools,
+			Version:     in.Spec.Version,
+			Firewall:    in.Spec.Firewall,
+			CniPlugin:   in.Spec.CniPlugin,
+			ClusterType: in.Spec.ClusterType,
+		},
+		Status: &opencpspec.KubernetesClusterStatus{
+			State: dryRunState,
+		},
+	}, nil
+}
@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDMetadataKey is the metadata key of the request ID, in the call and in the response headers
+const RequestIDMetadataKey = "x-request-id"
+
+// validRequestID is the request ID a caller can choose, the others are replaced
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDUnaryServerInterceptor gives each unary call a request ID, the one of the caller if it set one,
+// returns it in the response headers and puts it on every log line of the call
+func RequestIDUnaryServerInterceptor(logger *logrus.Entry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, id := withRequestID(ctx, logger)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, id))
+
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStreamServerInterceptor is the RequestIDUnaryServerInterceptor of the stream calls
+func RequestIDStreamServerInterceptor(logger *logrus.Entry) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		ctx, id := withRequestID(stream.Context(), logger)
+		wrapped.WrappedContext = ctx
+		stream.SetHeader(metadata.Pairs(RequestIDMetadataKey, id))
+
+		return handler(srv, wrapped)
+	}
+}
+
+// withRequestID adds the request ID and the logger of the call to the context
+func withRequestID(ctx context.Context, logger *logrus.Entry) (context.Context, string) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadataKey); len(values) > 0 && validRequestID.MatchString(values[0]) {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+
+	fields := logrus.Fields{"request_id": id}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		fields["trace_id"] = span.TraceID().String()
+	}
+
+	ctx = context.WithValue(ctx, "requestID", id)
+	return ctxlogrus.ToContext(ctx, logger.WithFields(fields)), id
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// Logger returns the logger of the call, with the request ID and the fields of the interceptors.
+// Outside of a call it is the standard logger
+func Logger(ctx context.Context) *logrus.Entry {
+	if _, ok := ctx.Value("requestID").(string); !ok {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	return ctxlogrus.Extract(ctx)
+}
+
+// CivoAPILogging is the ProviderCallHook logging the Civo API calls at debug level,
+// with the request ID of the gRPC call that makes them
+func CivoAPILogging() ProviderCallHook {
+	return func(ctx context.Context, endpoint string, next func() error) error {
+		start := time.Now()
+		err := next()
+
+		entry := Logger(ctx).WithFields(logrus.Fields{
+			"civo.endpoint": endpoint,
+			"civo.time_ns":  time.Since(start).Nanoseconds(),
+		})
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+		entry.Debug("Civo API call")
+
+		return err
+	}
+}
+
+// redacted replaces the secrets in the logs
+const redacted = "[REDACTED]"
+
+var (
+	// secretFieldNames are the parts of the names of the log fields holding secrets
+	secretFieldNames = []string{"token", "password", "secret", "authorization", "apikey", "api_key", "credential"}
+
+	// secretPatterns are the secrets inside the log messages and values, the first group is kept
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+`),
+		regexp.MustCompile(`(?i)((?:token|password|secret[_-]?(?:access[_-]?)?key|api[_-]?key)["']?\s*[:=]\s*["']?)[^\s"',&}]+`),
+	}
+)
+
+// RedactHook is the logrus hook removing the tokens, passwords and secret keys from every log line
+type RedactHook struct{}
+
+// Levels returns every level
+func (RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the fields named after a secret and the secrets found in the message and the other fields
+func (RedactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = redactString(entry.Message)
+	for key, value := range entry.Data {
+		entry.Data[key] = redactField(key, value)
+	}
+
+	return nil
+}
+
+// redactField returns the value of a log field without the secrets
+func redactField(key string, value interface{}) interface{} {
+	name := strings.ToLower(key)
+	for _, secret := range secretFieldNames {
+		if strings.Contains(name, secret) {
+			return redacted
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return redactString(v)
+	case error:
+		if s := redactString(v.Error()); s != v.Error() {
+			return s
+		}
+	case fmt.Stringer:
+		if s := redactString(v.String()); s != v.String() {
+			return s
+		}
+	case map[string]interface{}:
+		fields := make(map[string]interface{}, len(v))
+		for k, inner := range v {
+			fields[k] = redactField(k, inner)
+		}
+		return fields
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, inner := range v {
+			items[i] = redactField(key, inner)
+		}
+		return items
+	}
+
+	return value
+}
+
+// redactString removes the secrets found in a string
+func redactString(s string) string {
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+
+	return s
+}
@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactHook(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"grpc.request.authorization": "bearer abc123",
+		"db_password":                "hunter2",
+		"error":                      errors.New(`civo: api_key=abc123 rejected`),
+		"grpc.request.content":       map[string]interface{}{"name": "db1", "secretKey": "s3cr3t"},
+		"grpc.method":                "ListDatabase",
+	})
+	entry.Message = `calling with "authorization: Bearer abc123" and password: hunter2`
+
+	if err := (RedactHook{}).Fire(entry); err != nil {
+		t.Fatalf("redacting the entry: %v", err)
+	}
+
+	line, err := (&logrus.JSONFormatter{}).Format(entry)
+	if err != nil {
+		t.Fatalf("formatting the entry: %v", err)
+	}
+	for _, secret := range []string{"abc123", "hunter2", "s3cr3t"} {
+		if strings.Contains(string(line), secret) {
+			t.Errorf("the log line still has %s: %s", secret, line)
+		}
+	}
+	for _, kept := range []string{"ListDatabase", "db1", "rejected"} {
+		if !strings.Contains(string(line), kept) {
+			t.Errorf("the log line lost %s: %s", kept, line)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"context"
+	"sync"
+
+	"github.com/civo/civogo"
+)
+
+// lookupProvider is a provider listing the SSH keys and the disk images at most once per gRPC call.
+// A virtual machine call needs them several times to resolve and name the keys and the images of its
+// instances, and they don't change during the call unless the call changes them itself
+type lookupProvider struct {
+	Provider
+
+	mu         sync.Mutex
+	sshKeys    []civogo.SSHKey
+	diskImages []civogo.DiskImage
+}
+
+// cacheLookups replaces the provider of the context with one remembering the lookups of the call
+func cacheLookups(ctx context.Context) context.Context {
+	client, ok := ctx.Value("client").(Provider)
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, "client", &lookupProvider{Provider: client})
+}
+
+// ListSSHKeys returns the SSH keys of the account, listing them on the first call only
+func (p *lookupProvider) ListSSHKeys() ([]civogo.SSHKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sshKeys == nil {
+		sshKeys, err := p.Provider.ListSSHKeys()
+		if err != nil {
+			return nil, err
+		}
+		p.sshKeys = append([]civogo.SSHKey{}, sshKeys...)
+	}
+
+	return append([]civogo.SSHKey{}, p.sshKeys...), nil
+}
+
+// ListDiskImages returns the disk images, listing them on the first call only
+func (p *lookupProvider) ListDiskImages() ([]civogo.DiskImage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.diskImages == nil {
+		images, err := p.Provider.ListDiskImages()
+		if err != nil {
+			return nil, err
+		}
+		p.diskImages = append([]civogo.DiskImage{}, images...)
+	}
+
+	return append([]civogo.DiskImage{}, p.diskImages...), nil
+}
+
+// NewSSHKey uploads a new SSH public key and forgets the listed keys
+func (p *lookupProvider) NewSSHKey(name string, publicKey string) (*civogo.SimpleResponse, error) {
+	defer p.forgetSSHKeys()
+	return p.Provider.NewSSHKey(name, publicKey)
+}
+
+// DeleteSSHKey deletes an SSH key and forgets the listed keys
+func (p *lookupProvider) DeleteSSHKey(id string) (*civogo.SimpleResponse, error) {
+	defer p.forgetSSHKeys()
+	return p.Provider.DeleteSSHKey(id)
+}
+
+// forgetSSHKeys makes the next ListSSHKeys list the keys again
+func (p *lookupProvider) forgetSSHKeys() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sshKeys = nil
+}
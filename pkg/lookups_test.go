@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestProviderListsLookupsOncePerCall(t *testing.T) {
+	client, err := NewMemoryBackend().Provider("lookups-token", "LON1")
+	if err != nil {
+		t.Fatalf("creating the memory provider: %v", err)
+	}
+
+	calls := map[string]int{}
+	count := func(ctx context.Context, endpoint string, next func() error) error {
+		calls[endpoint]++
+		return next()
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		client := ctx.Value("client").(Provider)
+		for i := 0; i < 3; i++ {
+			if _, err := sshKeyNames(client); err != nil {
+				return nil, err
+			}
+			if _, err := resolveDiskImage(client, "ubuntu"); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := client.NewSSHKey("web", "ssh-ed25519 AAAA web"); err != nil {
+			return nil, err
+		}
+		return resolveSSHKey(client, "web")
+	}
+
+	ctx := context.WithValue(context.Background(), "client", client)
+	if _, err := ProviderUnaryServerInterceptor(count)(ctx, nil, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("running the call: %v", err)
+	}
+	if calls["ListSSHKeys"] != 2 {
+		t.Fatalf("the SSH keys were listed %d times, want once before and once after creating one", calls["ListSSHKeys"])
+	}
+	if calls["ListDiskImages"] != 1 {
+		t.Fatalf("the disk images were listed %d times, want once", calls["ListDiskImages"])
+	}
+}
@@ -0,0 +1,1338 @@
+package pkg
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/civo/civogo"
+)
+
+// memoryDefaultRegion is the region used by the in-memory backend when the call has none
+const memoryDefaultRegion = "LON1"
+
+// memoryRegions are the regions of the in-memory backend
+var memoryRegions = []civogo.Region{
+	{Code: "LON1", Name: "London 1", Type: "civostack", Country: "GB", CountryName: "United Kingdom", Features: civogo.Feature{Iaas: true, Kubernetes: true, ObjectStore: true}, Default: true},
+	{Code: "FRA1", Name: "Frankfurt 1", Type: "civostack", Country: "DE", CountryName: "Germany", Features: civogo.Feature{Iaas: true, Kubernetes: true, ObjectStore: true}},
+	{Code: "NYC1", Name: "New York 1", Type: "civostack", Country: "US", CountryName: "United States", Features: civogo.Feature{Iaas: true, Kubernetes: true, ObjectStore: true}},
+	{Code: "PHX1", Name: "Phoenix 1", Type: "civostack", Country: "US", CountryName: "United States", Features: civogo.Feature{Iaas: true, Kubernetes: true, ObjectStore: true}},
+}
+
+// memoryIPv6Regions are the regions of the in-memory backend whose networks have IPv6
+var memoryIPv6Regions = map[string]bool{"LON1": true, "FRA1": true}
+
+// memoryDiskImages are the disk images every in-memory account can launch
+var memoryDiskImages = []civogo.DiskImage{
+	{ID: "9a0b5e9c-c3e6-4d13-bd7c-7f4bd8ac0c5d", Name: "ubuntu-jammy", Version: "22.04", State: "available", Distribution: "ubuntu", Label: "jammy"},
+	{ID: "d927ad2f-5073-4ed6-b2eb-b8e61aef29a8", Name: "ubuntu-focal", Version: "20.04", State: "available", Distribution: "ubuntu", Label: "focal"},
+	{ID: "a4204155-a876-43fa-b4d6-ea2af8774560", Name: "debian-11", Version: "11", State: "available", Distribution: "debian", Label: "bullseye"},
+	{ID: "e4838e89-f086-41a1-86b2-60bc4b0a259e", Name: "debian-10", Version: "10", State: "available", Distribution: "debian", Label: "buster"},
+	{ID: "0682ccc7-fea5-4a5e-8e5c-6e4f8a8b8e6b", Name: "rocky-9-1", Version: "9.1", State: "available", Distribution: "rocky", Label: "rocky"},
+}
+
+// memoryInstanceSizes are the sizes of the instances, clusters and databases of the in-memory backend
+var memoryInstanceSizes = []civogo.InstanceSize{
+	{ID: "g3.xsmall", Name: "g3.xsmall", NiceName: "Extra Small", CPUCores: 1, RAMMegabytes: 1024, DiskGigabytes: 25, TransferTerabytes: 1, Selectable: true},
+	{ID: "g3.small", Name: "g3.small", NiceName: "Small", CPUCores: 1, RAMMegabytes: 2048, DiskGigabytes: 25, TransferTerabytes: 2, Selectable: true},
+	{ID: "g3.medium", Name: "g3.medium", NiceName: "Medium", CPUCores: 2, RAMMegabytes: 4096, DiskGigabytes: 50, TransferTerabytes: 3, Selectable: true},
+	{ID: "g3.large", Name: "g3.large", NiceName: "Large", CPUCores: 4, RAMMegabytes: 8192, DiskGigabytes: 100, TransferTerabytes: 4, Selectable: true},
+	{ID: "g4s.kube.small", Name: "g4s.kube.small", NiceName: "Small - Standard", CPUCores: 1, RAMMegabytes: 2048, DiskGigabytes: 40, TransferTerabytes: 2, Selectable: true},
+	{ID: "g4s.kube.medium", Name: "g4s.kube.medium", NiceName: "Medium - Standard", CPUCores: 2, RAMMegabytes: 4096, DiskGigabytes: 50, TransferTerabytes: 3, Selectable: true},
+	{ID: "g4s.kube.large", Name: "g4s.kube.large", NiceName: "Large - Standard", CPUCores: 4, RAMMegabytes: 8192, DiskGigabytes: 60, TransferTerabytes: 4, Selectable: true},
+	{ID: "g3.db.small", Name: "g3.db.small", NiceName: "Small - Database", CPUCores: 2, RAMMegabytes: 4096, DiskGigabytes: 40, Selectable: true},
+	{ID: "g3.db.medium", Name: "g3.db.medium", NiceName: "Medium - Database", CPUCores: 4, RAMMegabytes: 8192, DiskGigabytes: 80, Selectable: true},
+}
+
+// memoryKubernetesVersions are the versions a cluster of the in-memory backend can run
+var memoryKubernetesVersions = []civogo.KubernetesVersion{
+	{Label: "v1.27.1-k3s1", Version: "1.27.1-k3s1", Type: "stable", ClusterType: "k3s"},
+	{Label: "v1.26.4-k3s1", Version: "1.26.4-k3s1", Type: "stable", ClusterType: "k3s", Default: true},
+	{Label: "v1.25.9-k3s1", Version: "1.25.9-k3s1", Type: "stable", ClusterType: "k3s"},
+	{Label: "v1.27.1-talos", Version: "1.27.1", Type: "stable", ClusterType: "talos"},
+}
+
+// MemoryBackend keeps the Civo resources in memory so the server can run without the Civo API,
+// every token gets its own account and every account its own set of regions
+type MemoryBackend struct {
+	mu       sync.Mutex
+	accounts map[string]*memoryAccount
+}
+
+// memoryAccount holds the resources of one account, DNS and SSH keys are global in Civo
+type memoryAccount struct {
+	id        string
+	domains   []civogo.DNSDomain
+	records   []civogo.DNSRecord
+	sshKeys   []civogo.SSHKey
+	regions   map[string]*memoryRegion
+	ipCounter int
+}
+
+// memoryRegion holds the regional resources of an account
+type memoryRegion struct {
+	code         string
+	instances    []civogo.Instance
+	networks     []civogo.Network
+	firewalls    []civogo.Firewall
+	ips          []civogo.IP
+	databases    []civogo.Database
+	clusters     []civogo.KubernetesCluster
+	objectStores []civogo.ObjectStore
+	credentials  []civogo.ObjectStoreCredential
+}
+
+// memoryProvider is the Provider for one account and region of the in-memory backend
+type memoryProvider struct {
+	backend *MemoryBackend
+	token   string
+	region  string
+}
+
+// NewMemoryBackend creates an empty in-memory backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		accounts: map[string]*memoryAccount{},
+	}
+}
+
+// Provider returns the Provider for the account of the token, it can be used as a ProviderFactory
+func (b *MemoryBackend) Provider(token, region string) (Provider, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: no API Key supplied, this is required", civogo.NoAPIKeySuppliedError)
+	}
+
+	if region == "" {
+		region = memoryDefaultRegion
+	}
+
+	return &memoryProvider{backend: b, token: token, region: strings.ToUpper(region)}, nil
+}
+
+// account returns the account and region of the provider, creating them on first use.
+// The caller must hold the backend lock
+func (p *memoryProvider) account() (*memoryAccount, *memoryRegion) {
+	account, ok := p.backend.accounts[p.token]
+	if !ok {
+		sum := sha256.Sum256([]byte(p.token))
+		account = &memoryAccount{
+			id:      formatUUID(sum[:16]),
+			regions: map[string]*memoryRegion{},
+		}
+		p.backend.accounts[p.token] = account
+	}
+
+	region, ok := account.regions[p.region]
+	if !ok {
+		region = &memoryRegion{code: p.region}
+
+		// Every region starts with the default network and its firewall, like a new Civo account
+		network := civogo.Network{
+			ID:          newUUID(),
+			Name:        "cust-default-" + strings.ToLower(p.region),
+			Default:     true,
+			CIDR:        "192.168.1.0/24",
+			Label:       "default",
+			Status:      "Active",
+			IPv4Enabled: true,
+			IPv6Enabled: memoryIPv6Regions[p.region],
+		}
+		region.networks = append(region.networks, network)
+		region.firewalls = append(region.firewalls, civogo.Firewall{
+			ID:         newUUID(),
+			Name:       "default-default",
+			NetworkID:  network.ID,
+			Rules:      memoryDefaultRules(),
+			RulesCount: len(memoryDefaultRules()),
+		})
+		account.regions[p.region] = region
+	}
+
+	return account, region
+}
+
+// lock takes the backend lock and returns the account and region of the provider
+func (p *memoryProvider) lock() (*memoryAccount, *memoryRegion) {
+	p.backend.mu.Lock()
+	return p.account()
+}
+
+func (p *memoryProvider) unlock() {
+	p.backend.mu.Unlock()
+}
+
+// GetRegion returns the region of the provider
+func (p *memoryProvider) GetRegion() string {
+	return p.region
+}
+
+// GetAccountID returns the account ID, derived from the token
+func (p *memoryProvider) GetAccountID() string {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	return account.id
+}
+
+// ListAccounts returns the account of the token
+func (p *memoryProvider) ListAccounts() (*civogo.PaginatedAccounts, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	return &civogo.PaginatedAccounts{Page: 1, PerPage: 20, Pages: 1, Items: []civogo.Account{{ID: account.id}}}, nil
+}
+
+// ListRegions returns the regions of the in-memory backend
+func (p *memoryProvider) ListRegions() ([]civogo.Region, error) {
+	return append([]civogo.Region{}, memoryRegions...), nil
+}
+
+// ListAllInstances returns all the instances in the region
+func (p *memoryProvider) ListAllInstances() ([]civogo.Instance, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return append([]civogo.Instance{}, region.instances...), nil
+}
+
+// FindInstance finds an instance by part of the ID or the hostname
+func (p *memoryProvider) FindInstance(search string) (*civogo.Instance, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.instances, search, func(i civogo.Instance) []string { return []string{i.ID, i.Hostname} })
+}
+
+// CreateInstance creates a new instance, the instance is active straight away
+func (p *memoryProvider) CreateInstance(config *civogo.InstanceConfig) (*civogo.Instance, error) {
+	account, region := p.lock()
+	defer p.unlock()
+
+	if config.Size == "" {
+		return nil, fmt.Errorf("%w: size is required", civogo.ParameterSizeMissingError)
+	}
+
+	if _, err := memoryFindExact(region.instances, config.Hostname, func(i civogo.Instance) string { return i.Hostname }); err == nil {
+		return nil, fmt.Errorf("%w: an instance with the hostname %s already exists", civogo.DatabaseInstanceDuplicateError, config.Hostname)
+	}
+
+	diskImage, err := memoryFindExact(memoryDiskImages, config.TemplateID, func(d civogo.DiskImage) string { return d.ID })
+	if err != nil {
+		return nil, fmt.Errorf("%w: disk image %s not found", civogo.DatabaseDiskImageNotFoundError, config.TemplateID)
+	}
+
+	network, err := region.network(config.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	firewall, err := region.firewall(config.FirewallID, network)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SSHKeyID != "" {
+		if _, err := memoryFindExact(account.sshKeys, config.SSHKeyID, func(k civogo.SSHKey) string { return k.ID }); err != nil {
+			return nil, fmt.Errorf("%w: SSH key %s not found", civogo.DatabaseSSHKeyNotFoundError, config.SSHKeyID)
+		}
+	}
+
+	initialUser := config.InitialUser
+	if initialUser == "" {
+		initialUser = "civo"
+	}
+
+	instance := civogo.Instance{
+		ID:              newUUID(),
+		Hostname:        config.Hostname,
+		ReverseDNS:      config.ReverseDNS,
+		Size:            config.Size,
+		Region:          region.code,
+		NetworkID:       network.ID,
+		PrivateIP:       fmt.Sprintf("192.168.1.%d", len(region.instances)+2),
+		TemplateID:      diskImage.ID,
+		SourceType:      "diskimage",
+		SourceID:        diskImage.ID,
+		InitialUser:     initialUser,
+		InitialPassword: randomString(16, passwordChars),
+		SSHKeyID:        config.SSHKeyID,
+		Status:          "ACTIVE",
+		FirewallID:      firewall.ID,
+		Tags:            config.Tags,
+		Script:          config.Script,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	if config.PublicIPRequired != "false" {
+		instance.PublicIP = account.nextPublicIP()
+	}
+	if network.IPv6Enabled {
+		instance.IPv6 = account.nextPublicIPv6()
+	}
+
+	firewall.InstanceCount++
+	region.instances = append(region.instances, instance)
+
+	return &instance, nil
+}
+
+// DeleteInstance deletes an instance
+func (p *memoryProvider) DeleteInstance(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, instance := range region.instances {
+		if instance.ID == id {
+			if fw, err := region.firewall(instance.FirewallID, nil); err == nil {
+				fw.InstanceCount--
+			}
+			region.instances = append(region.instances[:i], region.instances[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: instance %s not found", civogo.DatabaseInstanceNotFoundError, id)
+}
+
+// UpgradeInstance resizes an instance, Civo can't make the disk smaller
+func (p *memoryProvider) UpgradeInstance(id, newSize string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	instance, err := region.instance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := memoryFindExact(memoryInstanceSizes, newSize, func(s civogo.InstanceSize) string { return s.Name })
+	if err != nil {
+		return nil, fmt.Errorf("%w: size %s not found", civogo.DatabaseSizeNotFoundError, newSize)
+	}
+	if current, err := memoryFindExact(memoryInstanceSizes, instance.Size, func(s civogo.InstanceSize) string { return s.Name }); err == nil && size.DiskGigabytes < current.DiskGigabytes {
+		return nil, fmt.Errorf("%w: the disk of size %s is smaller than the one of %s", civogo.OpenstackInstanceResizeError, newSize, instance.Size)
+	}
+
+	instance.Size = size.Name
+	return memorySuccess(id), nil
+}
+
+// SetInstanceTags replaces the tags of an instance, separated by spaces
+func (p *memoryProvider) SetInstanceTags(i *civogo.Instance, tags string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	instance, err := region.instance(i.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.Tags = strings.Fields(tags)
+	return memorySuccess(i.ID), nil
+}
+
+// SetInstanceFirewall moves an instance to a firewall of its network
+func (p *memoryProvider) SetInstanceFirewall(id, firewallID string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	instance, err := region.instance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	firewall, err := region.firewall(firewallID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if firewall.NetworkID != instance.NetworkID {
+		return nil, fmt.Errorf("%w: firewall %s is not in the network of the instance", civogo.DatabaseFirewallNotFoundError, firewallID)
+	}
+
+	if previous, err := region.firewall(instance.FirewallID, nil); err == nil {
+		previous.InstanceCount--
+	}
+	firewall.InstanceCount++
+	instance.FirewallID = firewall.ID
+
+	return memorySuccess(id), nil
+}
+
+// RebuildInstance reinstalls an instance from a disk image
+func (p *memoryProvider) RebuildInstance(id, templateID string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	instance, err := region.instance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	diskImage, err := memoryFindExact(memoryDiskImages, templateID, func(d civogo.DiskImage) string { return d.ID })
+	if err != nil {
+		return nil, fmt.Errorf("%w: disk image %s not found", civogo.DatabaseDiskImageNotFoundError, templateID)
+	}
+
+	instance.TemplateID = diskImage.ID
+	instance.SourceID = diskImage.ID
+	instance.InitialPassword = randomString(16, passwordChars)
+
+	return memorySuccess(id), nil
+}
+
+// StartInstance starts an instance, it is active straight away
+func (p *memoryProvider) StartInstance(id string) (*civogo.SimpleResponse, error) {
+	return p.setInstanceStatus(id, "ACTIVE")
+}
+
+// StopInstance stops an instance, it is shut off straight away
+func (p *memoryProvider) StopInstance(id string) (*civogo.SimpleResponse, error) {
+	return p.setInstanceStatus(id, "SHUTOFF")
+}
+
+// SoftRebootInstance reboots an instance, it is active again straight away
+func (p *memoryProvider) SoftRebootInstance(id string) (*civogo.SimpleResponse, error) {
+	return p.setInstanceStatus(id, "ACTIVE")
+}
+
+// HardRebootInstance power cycles an instance, it is active again straight away
+func (p *memoryProvider) HardRebootInstance(id string) (*civogo.SimpleResponse, error) {
+	return p.setInstanceStatus(id, "ACTIVE")
+}
+
+// setInstanceStatus sets the status of an instance
+func (p *memoryProvider) setInstanceStatus(id, status string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	instance, err := region.instance(id)
+	if err != nil {
+		return nil, err
+	}
+
+	instance.Status = status
+	return memorySuccess(id), nil
+}
+
+// ListDiskImages returns the disk images of the in-memory backend
+func (p *memoryProvider) ListDiskImages() ([]civogo.DiskImage, error) {
+	return append([]civogo.DiskImage{}, memoryDiskImages...), nil
+}
+
+// ListInstanceSizes returns the sizes of the in-memory backend
+func (p *memoryProvider) ListInstanceSizes() ([]civogo.InstanceSize, error) {
+	return append([]civogo.InstanceSize{}, memoryInstanceSizes...), nil
+}
+
+// ListNetworks returns all the networks in the region
+func (p *memoryProvider) ListNetworks() ([]civogo.Network, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return append([]civogo.Network{}, region.networks...), nil
+}
+
+// FindNetwork finds a network by part of the ID or the label
+func (p *memoryProvider) FindNetwork(search string) (*civogo.Network, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.networks, search, func(n civogo.Network) []string { return []string{n.ID, n.Label} })
+}
+
+// NewNetwork creates a new private network
+func (p *memoryProvider) NewNetwork(label string) (*civogo.NetworkResult, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(region.networks, label, func(n civogo.Network) string { return n.Label }); err == nil {
+		return nil, fmt.Errorf("%w: a network with the label %s already exists", civogo.DatabaseNetworkDuplicateNameError, label)
+	}
+
+	network := civogo.Network{
+		ID:          newUUID(),
+		Name:        "cust-" + strings.ToLower(label),
+		CIDR:        fmt.Sprintf("192.168.%d.0/24", len(region.networks)+1),
+		Label:       label,
+		Status:      "Active",
+		IPv4Enabled: true,
+		IPv6Enabled: memoryIPv6Regions[p.region],
+	}
+	region.networks = append(region.networks, network)
+
+	return &civogo.NetworkResult{ID: network.ID, Label: network.Label, Result: civogo.ResultSuccess}, nil
+}
+
+// DeleteNetwork deletes a network, the default network and networks in use can't be deleted
+func (p *memoryProvider) DeleteNetwork(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, network := range region.networks {
+		if network.ID != id {
+			continue
+		}
+
+		if network.Default {
+			return nil, fmt.Errorf("%w: the default network can't be deleted", civogo.NetworkDeleteDefaultError)
+		}
+
+		for _, instance := range region.instances {
+			if instance.NetworkID == id {
+				return nil, fmt.Errorf("%w: the network still has instances", civogo.DatabaseNetworkDeleteWithInstanceError)
+			}
+		}
+
+		region.networks = append(region.networks[:i], region.networks[i+1:]...)
+		return memorySuccess(id), nil
+	}
+
+	return nil, fmt.Errorf("%w: network %s not found", civogo.DatabaseNetworkNotFoundError, id)
+}
+
+// ListFirewalls returns all the firewalls in the region
+func (p *memoryProvider) ListFirewalls() ([]civogo.Firewall, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return append([]civogo.Firewall{}, region.firewalls...), nil
+}
+
+// FindFirewall finds a firewall by part of the ID or the name
+func (p *memoryProvider) FindFirewall(search string) (*civogo.Firewall, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.firewalls, search, func(f civogo.Firewall) []string { return []string{f.ID, f.Name} })
+}
+
+// NewFirewall creates a new firewall, with the default rules unless told otherwise
+func (p *memoryProvider) NewFirewall(config *civogo.FirewallConfig) (*civogo.FirewallResult, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(region.firewalls, config.Name, func(f civogo.Firewall) string { return f.Name }); err == nil {
+		return nil, fmt.Errorf("%w: a firewall with the name %s already exists", civogo.DatabaseFirewallDuplicateNameError, config.Name)
+	}
+
+	network, err := region.network(config.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	firewall := civogo.Firewall{
+		ID:        newUUID(),
+		Name:      config.Name,
+		NetworkID: network.ID,
+	}
+
+	rules := config.Rules
+	if config.CreateRules == nil || *config.CreateRules {
+		rules = append(memoryDefaultRules(), rules...)
+	}
+
+	for _, rule := range rules {
+		rule.ID = newUUID()
+		rule.FirewallID = firewall.ID
+		firewall.Rules = append(firewall.Rules, rule)
+	}
+	firewall.RulesCount = len(firewall.Rules)
+
+	region.firewalls = append(region.firewalls, firewall)
+
+	return &civogo.FirewallResult{ID: firewall.ID, Name: firewall.Name, Result: civogo.ResultSuccess}, nil
+}
+
+// DeleteFirewall deletes a firewall, firewalls in use can't be deleted
+func (p *memoryProvider) DeleteFirewall(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, firewall := range region.firewalls {
+		if firewall.ID != id {
+			continue
+		}
+
+		if firewall.InstanceCount > 0 || firewall.ClusterCount > 0 || firewall.LoadBalancerCount > 0 {
+			return nil, fmt.Errorf("%w: the firewall is still in use", civogo.DatabaseFirewallDeleteFailedError)
+		}
+
+		region.firewalls = append(region.firewalls[:i], region.firewalls[i+1:]...)
+		return memorySuccess(id), nil
+	}
+
+	return nil, fmt.Errorf("%w: firewall %s not found", civogo.DatabaseFirewallNotFoundError, id)
+}
+
+// ListDNSDomains returns all the DNS domains of the account
+func (p *memoryProvider) ListDNSDomains() ([]civogo.DNSDomain, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	return append([]civogo.DNSDomain{}, account.domains...), nil
+}
+
+// FindDNSDomain finds a DNS domain by part of the ID or the name
+func (p *memoryProvider) FindDNSDomain(search string) (*civogo.DNSDomain, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	return memoryFind(account.domains, search, func(d civogo.DNSDomain) []string { return []string{d.ID, d.Name} })
+}
+
+// CreateDNSDomain creates a new DNS domain
+func (p *memoryProvider) CreateDNSDomain(name string) (*civogo.DNSDomain, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(account.domains, name, func(d civogo.DNSDomain) string { return d.Name }); err == nil {
+		return nil, fmt.Errorf("%w: the domain %s already exists", civogo.DatabaseDNSDomainDuplicateNameError, name)
+	}
+
+	domain := civogo.DNSDomain{
+		ID:        newUUID(),
+		AccountID: account.id,
+		Name:      name,
+	}
+	account.domains = append(account.domains, domain)
+
+	return &domain, nil
+}
+
+// DeleteDNSDomain deletes a DNS domain and all its records
+func (p *memoryProvider) DeleteDNSDomain(d *civogo.DNSDomain) (*civogo.SimpleResponse, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	for i, domain := range account.domains {
+		if domain.ID != d.ID {
+			continue
+		}
+
+		records := []civogo.DNSRecord{}
+		for _, record := range account.records {
+			if record.DNSDomainID != domain.ID {
+				records = append(records, record)
+			}
+		}
+		account.records = records
+
+		account.domains = append(account.domains[:i], account.domains[i+1:]...)
+		return memorySuccess(d.ID), nil
+	}
+
+	return nil, fmt.Errorf("%w: domain %s not found", civogo.DatabaseDNSDomainNotFoundError, d.ID)
+}
+
+// ListDNSRecords returns all the records of a DNS domain
+func (p *memoryProvider) ListDNSRecords(dnsDomainID string) ([]civogo.DNSRecord, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(account.domains, dnsDomainID, func(d civogo.DNSDomain) string { return d.ID }); err != nil {
+		return nil, fmt.Errorf("%w: domain %s not found", civogo.DatabaseDNSDomainNotFoundError, dnsDomainID)
+	}
+
+	records := []civogo.DNSRecord{}
+	for _, record := range account.records {
+		if record.DNSDomainID == dnsDomainID {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// CreateDNSRecord creates a new record in a DNS domain
+func (p *memoryProvider) CreateDNSRecord(domainID string, r *civogo.DNSRecordConfig) (*civogo.DNSRecord, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(account.domains, domainID, func(d civogo.DNSDomain) string { return d.ID }); err != nil {
+		return nil, fmt.Errorf("%w: domain %s not found", civogo.DatabaseDNSDomainNotFoundError, domainID)
+	}
+
+	switch r.Type {
+	case civogo.DNSRecordTypeA, civogo.DNSRecordTypeCName, civogo.DNSRecordTypeMX, civogo.DNSRecordTypeSRV, civogo.DNSRecordTypeTXT:
+	default:
+		return nil, fmt.Errorf("%w: invalid record type %s", civogo.ParameterDNSRecordTypeError, r.Type)
+	}
+
+	ttl := r.TTL
+	if ttl == 0 {
+		ttl = 600
+	}
+
+	now := time.Now().UTC()
+	record := civogo.DNSRecord{
+		ID:          newUUID(),
+		AccountID:   account.id,
+		DNSDomainID: domainID,
+		Name:        r.Name,
+		Value:       r.Value,
+		Type:        r.Type,
+		Priority:    r.Priority,
+		TTL:         ttl,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	account.records = append(account.records, record)
+
+	return &record, nil
+}
+
+// ListSSHKeys returns all the SSH keys of the account
+func (p *memoryProvider) ListSSHKeys() ([]civogo.SSHKey, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	return append([]civogo.SSHKey{}, account.sshKeys...), nil
+}
+
+// FindSSHKey finds an SSH key by part of the ID or the name
+func (p *memoryProvider) FindSSHKey(search string) (*civogo.SSHKey, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	return memoryFind(account.sshKeys, search, func(k civogo.SSHKey) []string { return []string{k.ID, k.Name} })
+}
+
+// NewSSHKey uploads a new SSH public key
+func (p *memoryProvider) NewSSHKey(name string, publicKey string) (*civogo.SimpleResponse, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	if publicKey == "" {
+		return nil, fmt.Errorf("%w: the public key can't be empty", civogo.ParameterPublicKeyEmptyError)
+	}
+
+	if _, err := memoryFindExact(account.sshKeys, name, func(k civogo.SSHKey) string { return k.Name }); err == nil {
+		return nil, fmt.Errorf("%w: an SSH key with the name %s already exists", civogo.DatabaseSSHKeyDuplicateNameError, name)
+	}
+
+	sshKey := civogo.SSHKey{
+		ID:          newUUID(),
+		Name:        name,
+		Fingerprint: sshFingerprint(publicKey),
+		PublicKey:   publicKey,
+		CreatedAt:   time.Now().UTC(),
+	}
+	account.sshKeys = append(account.sshKeys, sshKey)
+
+	return memorySuccess(sshKey.ID), nil
+}
+
+// DeleteSSHKey deletes an SSH key
+func (p *memoryProvider) DeleteSSHKey(id string) (*civogo.SimpleResponse, error) {
+	account, _ := p.lock()
+	defer p.unlock()
+
+	for i, sshKey := range account.sshKeys {
+		if sshKey.ID == id {
+			account.sshKeys = append(account.sshKeys[:i], account.sshKeys[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: SSH key %s not found", civogo.DatabaseSSHKeyNotFoundError, id)
+}
+
+// ListIPs returns all the reserved IPs in the region
+func (p *memoryProvider) ListIPs() (*civogo.PaginatedIPs, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return &civogo.PaginatedIPs{
+		Page:    1,
+		PerPage: len(region.ips),
+		Pages:   1,
+		Items:   append([]civogo.IP{}, region.ips...),
+	}, nil
+}
+
+// FindIP finds a reserved IP by part of the ID, the name or the address
+func (p *memoryProvider) FindIP(search string) (*civogo.IP, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.ips, search, func(ip civogo.IP) []string { return []string{ip.ID, ip.Name, ip.IP} })
+}
+
+// NewIP reserves a new public IP, the name defaults to the address
+func (p *memoryProvider) NewIP(v *civogo.CreateIPRequest) (*civogo.IP, error) {
+	account, region := p.lock()
+	defer p.unlock()
+
+	ip := civogo.IP{
+		ID:   newUUID(),
+		Name: v.Name,
+		IP:   account.nextPublicIP(),
+	}
+	if ip.Name == "" {
+		ip.Name = ip.IP
+	}
+	region.ips = append(region.ips, ip)
+
+	return &ip, nil
+}
+
+// DeleteIP releases a reserved IP
+func (p *memoryProvider) DeleteIP(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, ip := range region.ips {
+		if ip.ID == id {
+			region.ips = append(region.ips[:i], region.ips[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: IP %s not found", civogo.ZeroMatchesError, id)
+}
+
+// ListDatabases returns all the databases in the region
+func (p *memoryProvider) ListDatabases() (*civogo.PaginatedDatabases, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return &civogo.PaginatedDatabases{
+		Page:    1,
+		PerPage: len(region.databases),
+		Pages:   1,
+		Items:   append([]civogo.Database{}, region.databases...),
+	}, nil
+}
+
+// FindDatabase finds a database by part of the ID or the name
+func (p *memoryProvider) FindDatabase(search string) (*civogo.Database, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.databases, search, func(d civogo.Database) []string { return []string{d.ID, d.Name} })
+}
+
+// NewDatabase creates a new MySQL database, the database is ready straight away
+func (p *memoryProvider) NewDatabase(v *civogo.CreateDatabaseRequest) (*civogo.Database, error) {
+	account, region := p.lock()
+	defer p.unlock()
+
+	if v.Size == "" {
+		return nil, fmt.Errorf("%w: size is required", civogo.ParameterSizeMissingError)
+	}
+
+	if _, err := memoryFindExact(region.databases, v.Name, func(d civogo.Database) string { return d.Name }); err == nil {
+		return nil, memoryConflictError(fmt.Sprintf("a database with the name %s already exists", v.Name))
+	}
+
+	network, err := region.network(v.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	firewall, err := region.firewall(v.FirewallID, network)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := v.Nodes
+	if nodes == 0 {
+		nodes = 1
+	}
+
+	database := civogo.Database{
+		ID:              newUUID(),
+		Name:            v.Name,
+		Nodes:           nodes,
+		Size:            v.Size,
+		Software:        "MySQL",
+		SoftwareVersion: "8.0",
+		PublicIPv4:      account.nextPublicIP(),
+		NetworkID:       network.ID,
+		FirewallID:      firewall.ID,
+		Port:            3306,
+		Username:        "root",
+		Password:        randomString(24, passwordChars),
+		Status:          "Ready",
+	}
+	region.databases = append(region.databases, database)
+
+	return &database, nil
+}
+
+// DeleteDatabase deletes a database
+func (p *memoryProvider) DeleteDatabase(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, database := range region.databases {
+		if database.ID == id {
+			region.databases = append(region.databases[:i], region.databases[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: database %s not found", civogo.DatabaseInstanceNotFoundError, id)
+}
+
+// ListKubernetesClusters returns all the Kubernetes clusters in the region
+func (p *memoryProvider) ListKubernetesClusters() (*civogo.PaginatedKubernetesClusters, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return &civogo.PaginatedKubernetesClusters{
+		Page:    1,
+		PerPage: len(region.clusters),
+		Pages:   1,
+		Items:   append([]civogo.KubernetesCluster{}, region.clusters...),
+	}, nil
+}
+
+// FindKubernetesCluster finds a Kubernetes cluster by part of the ID or the name
+func (p *memoryProvider) FindKubernetesCluster(search string) (*civogo.KubernetesCluster, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.clusters, search, func(k civogo.KubernetesCluster) []string { return []string{k.ID, k.Name} })
+}
+
+// NewKubernetesClusters creates a new Kubernetes cluster, the cluster is active straight away
+func (p *memoryProvider) NewKubernetesClusters(kc *civogo.KubernetesClusterConfig) (*civogo.KubernetesCluster, error) {
+	account, region := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(region.clusters, kc.Name, func(k civogo.KubernetesCluster) string { return k.Name }); err == nil {
+		return nil, fmt.Errorf("%w: a cluster with the name %s already exists", civogo.DatabaseKubernetesClusterDuplicateError, kc.Name)
+	}
+
+	if len(kc.Pools) == 0 {
+		return nil, fmt.Errorf("%w: a cluster needs at least one pool", civogo.DatabaseKubernetesClusterNoPoolsError)
+	}
+
+	network, err := region.network(kc.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	firewall, err := region.firewall(kc.InstanceFirewall, network)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := civogo.KubernetesCluster{
+		ID:          newUUID(),
+		Name:        kc.Name,
+		Version:     kc.KubernetesVersion,
+		Status:      "ACTIVE",
+		Ready:       true,
+		ClusterType: kc.ClusterType,
+		NetworkID:   network.ID,
+		FirewallID:  firewall.ID,
+		CNIPlugin:   kc.CNIPlugin,
+		MasterIP:    account.nextPublicIP(),
+		CreatedAt:   time.Now().UTC(),
+		BuiltAt:     time.Now().UTC(),
+	}
+
+	if cluster.Version == "" {
+		cluster.Version = "1.26.4-k3s1"
+	}
+	if cluster.ClusterType == "" {
+		cluster.ClusterType = "k3s"
+	}
+	if cluster.CNIPlugin == "" {
+		cluster.CNIPlugin = "flannel"
+	}
+	cluster.KubernetesVersion = cluster.Version
+	cluster.APIEndPoint = fmt.Sprintf("https://%s:6443", cluster.MasterIP)
+	cluster.DNSEntry = fmt.Sprintf("%s.k8s.civo.com", cluster.ID)
+	cluster.KubeConfig = memoryKubeconfig(cluster)
+
+	for _, pool := range kc.Pools {
+		id := pool.ID
+		if id == "" {
+			id = newUUID()
+		}
+		cluster.Pools = append(cluster.Pools, civogo.KubernetesPool{ID: id, Count: pool.Count, Size: pool.Size})
+		cluster.NumTargetNode += pool.Count
+		cluster.TargetNodeSize = pool.Size
+	}
+
+	firewall.ClusterCount++
+	region.clusters = append(region.clusters, cluster)
+
+	return &cluster, nil
+}
+
+// ListAvailableKubernetesVersions returns the versions a cluster of the in-memory backend can run
+func (p *memoryProvider) ListAvailableKubernetesVersions() ([]civogo.KubernetesVersion, error) {
+	return append([]civogo.KubernetesVersion{}, memoryKubernetesVersions...), nil
+}
+
+// DeleteKubernetesCluster deletes a Kubernetes cluster
+func (p *memoryProvider) DeleteKubernetesCluster(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, cluster := range region.clusters {
+		if cluster.ID == id {
+			if fw, err := region.firewall(cluster.FirewallID, nil); err == nil {
+				fw.ClusterCount--
+			}
+			region.clusters = append(region.clusters[:i], region.clusters[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: cluster %s not found", civogo.DatabaseKubernetesClusterNotFoundError, id)
+}
+
+// ListObjectStores returns all the object stores in the region
+func (p *memoryProvider) ListObjectStores() (*civogo.PaginatedObjectstores, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return &civogo.PaginatedObjectstores{
+		Page:    1,
+		PerPage: len(region.objectStores),
+		Pages:   1,
+		Items:   append([]civogo.ObjectStore{}, region.objectStores...),
+	}, nil
+}
+
+// FindObjectStore finds an object store by part of the ID or the name
+func (p *memoryProvider) FindObjectStore(search string) (*civogo.ObjectStore, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.objectStores, search, func(o civogo.ObjectStore) []string { return []string{o.ID, o.Name} })
+}
+
+// NewObjectStore creates a new object store, with a new credential unless an access key is given
+func (p *memoryProvider) NewObjectStore(v *civogo.CreateObjectStoreRequest) (*civogo.ObjectStore, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(region.objectStores, v.Name, func(o civogo.ObjectStore) string { return o.Name }); err == nil {
+		return nil, memoryConflictError(fmt.Sprintf("an object store with the name %s already exists", v.Name))
+	}
+
+	var credential civogo.ObjectStoreCredential
+	if v.AccessKeyID != "" {
+		c, err := memoryFindExact(region.credentials, v.AccessKeyID, func(c civogo.ObjectStoreCredential) string { return c.AccessKeyID })
+		if err != nil {
+			return nil, err
+		}
+		credential = *c
+	} else {
+		credential = region.newCredential(v.Name, "", "")
+	}
+
+	maxSize := int(v.MaxSizeGB)
+	if maxSize == 0 {
+		maxSize = 500
+	}
+
+	objectStore := civogo.ObjectStore{
+		ID:      newUUID(),
+		Name:    v.Name,
+		MaxSize: maxSize,
+		OwnerInfo: civogo.BucketOwner{
+			AccessKeyID:  credential.AccessKeyID,
+			Name:         credential.Name,
+			CredentialID: credential.ID,
+		},
+		BucketURL: fmt.Sprintf("objectstore.%s.civo.com", strings.ToLower(region.code)),
+		Status:    "ready",
+	}
+	region.objectStores = append(region.objectStores, objectStore)
+
+	return &objectStore, nil
+}
+
+// DeleteObjectStore deletes an object store
+func (p *memoryProvider) DeleteObjectStore(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, objectStore := range region.objectStores {
+		if objectStore.ID == id {
+			region.objectStores = append(region.objectStores[:i], region.objectStores[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: object store %s not found", civogo.ZeroMatchesError, id)
+}
+
+// ListObjectStoreCredentials returns all the object store credentials in the region
+func (p *memoryProvider) ListObjectStoreCredentials() (*civogo.PaginatedObjectStoreCredentials, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return &civogo.PaginatedObjectStoreCredentials{
+		Page:    1,
+		PerPage: len(region.credentials),
+		Pages:   1,
+		Items:   append([]civogo.ObjectStoreCredential{}, region.credentials...),
+	}, nil
+}
+
+// FindObjectStoreCredential finds an object store credential by part of the ID or the name
+func (p *memoryProvider) FindObjectStoreCredential(search string) (*civogo.ObjectStoreCredential, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	return memoryFind(region.credentials, search, func(c civogo.ObjectStoreCredential) []string { return []string{c.ID, c.Name} })
+}
+
+// NewObjectStoreCredential creates a new object store credential, the keys are generated when not given
+func (p *memoryProvider) NewObjectStoreCredential(v *civogo.CreateObjectStoreCredentialRequest) (*civogo.ObjectStoreCredential, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	if _, err := memoryFindExact(region.credentials, v.Name, func(c civogo.ObjectStoreCredential) string { return c.Name }); err == nil {
+		return nil, fmt.Errorf("%w: a credential with the name %s already exists", civogo.DatabaseAPIKeyDuplicateError, v.Name)
+	}
+
+	var accessKey, secretKey string
+	if v.AccessKeyID != nil {
+		accessKey = *v.AccessKeyID
+	}
+	if v.SecretAccessKeyID != nil {
+		secretKey = *v.SecretAccessKeyID
+	}
+
+	credential := region.newCredential(v.Name, accessKey, secretKey)
+	return &credential, nil
+}
+
+// DeleteObjectStoreCredential deletes an object store credential
+func (p *memoryProvider) DeleteObjectStoreCredential(id string) (*civogo.SimpleResponse, error) {
+	_, region := p.lock()
+	defer p.unlock()
+
+	for i, credential := range region.credentials {
+		if credential.ID == id {
+			region.credentials = append(region.credentials[:i], region.credentials[i+1:]...)
+			return memorySuccess(id), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: credential %s not found", civogo.DatabaseAPIKeyNotFoundError, id)
+}
+
+// network returns the network with the ID, or the default network when the ID is empty
+func (r *memoryRegion) network(id string) (*civogo.Network, error) {
+	for i := range r.networks {
+		if (id == "" && r.networks[i].Default) || r.networks[i].ID == id {
+			return &r.networks[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: network %s not found", civogo.DatabaseNetworkNotFoundError, id)
+}
+
+// instance returns the instance with the ID
+func (r *memoryRegion) instance(id string) (*civogo.Instance, error) {
+	for i := range r.instances {
+		if r.instances[i].ID == id {
+			return &r.instances[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: instance %s not found", civogo.DatabaseInstanceNotFoundError, id)
+}
+
+// firewall returns the firewall with the ID, or the first firewall of the network when the ID is empty
+func (r *memoryRegion) firewall(id string, network *civogo.Network) (*civogo.Firewall, error) {
+	for i := range r.firewalls {
+		if id == "" && network != nil && r.firewalls[i].NetworkID == network.ID {
+			return &r.firewalls[i], nil
+		}
+		if id != "" && r.firewalls[i].ID == id {
+			return &r.firewalls[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: firewall %s not found", civogo.DatabaseFirewallNotFoundError, id)
+}
+
+// newCredential adds a new object store credential to the region, generating the missing keys,
+// and returns a copy of it
+func (r *memoryRegion) newCredential(name, accessKey, secretKey string) civogo.ObjectStoreCredential {
+	if accessKey == "" {
+		accessKey = randomString(20, accessKeyChars)
+	}
+	if secretKey == "" {
+		secretKey = randomString(40, passwordChars)
+	}
+
+	credential := civogo.ObjectStoreCredential{
+		ID:                newUUID(),
+		Name:              name,
+		AccessKeyID:       accessKey,
+		SecretAccessKeyID: secretKey,
+		MaxSizeGB:         500,
+		Status:            "ready",
+	}
+	r.credentials = append(r.credentials, credential)
+
+	return credential
+}
+
+// nextPublicIP hands out the next public IP of the account
+func (a *memoryAccount) nextPublicIP() string {
+	a.ipCounter++
+	return fmt.Sprintf("74.220.%d.%d", 16+a.ipCounter/250, 2+a.ipCounter%250)
+}
+
+// nextPublicIPv6 returns a new public IPv6 address of the account
+func (a *memoryAccount) nextPublicIPv6() string {
+	a.ipCounter++
+	return fmt.Sprintf("2a0a:f7c0:4:%x::%x", a.ipCounter/0x10000, 2+a.ipCounter%0x10000)
+}
+
+// memoryConflictError is the error civogo returns for a 409 with no error of its own, such as a
+// database or an object store name already in use
+func memoryConflictError(reason string) error {
+	return fmt.Errorf("%w: Unknown error response - status: 409 Conflict, code: 409, reason: %s", civogo.CommonError, reason)
+}
+
+// memoryFind searches the items like civogo does, an exact match on any of the keys wins,
+// otherwise the search must be part of the keys of only one item
+func memoryFind[T any](items []T, search string, keys func(T) []string) (*T, error) {
+	var partial []int
+	for i, item := range items {
+		for _, key := range keys(item) {
+			if key == search {
+				result := items[i]
+				return &result, nil
+			}
+		}
+
+		for _, key := range keys(item) {
+			if strings.Contains(key, search) {
+				partial = append(partial, i)
+				break
+			}
+		}
+	}
+
+	switch len(partial) {
+	case 0:
+		return nil, fmt.Errorf("%w: unable to find %s, zero matches", civogo.ZeroMatchesError, search)
+	case 1:
+		result := items[partial[0]]
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("%w: unable to find %s because there were multiple matches", civogo.MultipleMatchesError, search)
+	}
+}
+
+// memoryFindExact returns the item whose key is exactly the value
+func memoryFindExact[T any](items []T, value string, key func(T) string) (*T, error) {
+	for i := range items {
+		if key(items[i]) == value {
+			return &items[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unable to find %s, zero matches", civogo.ZeroMatchesError, value)
+}
+
+// memoryDefaultRules are the rules Civo adds to a new firewall
+func memoryDefaultRules() []civogo.FirewallRule {
+	return []civogo.FirewallRule{
+		{Protocol: "tcp", StartPort: "1", EndPort: "65535", Ports: "1-65535", Cidr: []string{"0.0.0.0/0"}, Direction: "ingress", Action: "allow", Label: "All TCP ports open"},
+		{Protocol: "udp", StartPort: "1", EndPort: "65535", Ports: "1-65535", Cidr: []string{"0.0.0.0/0"}, Direction: "ingress", Action: "allow", Label: "All UDP ports open"},
+		{Protocol: "icmp", Cidr: []string{"0.0.0.0/0"}, Direction: "ingress", Action: "allow", Label: "Ping/traceroute"},
+	}
+}
+
+// memoryKubeconfig returns a kubeconfig pointing to the API endpoint of the cluster
+func memoryKubeconfig(cluster civogo.KubernetesCluster) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %[1]s
+  name: %[2]s
+contexts:
+- context:
+    cluster: %[2]s
+    user: %[2]s
+  name: %[2]s
+current-context: %[2]s
+users:
+- name: %[2]s
+  user:
+    token: %[3]s
+`, cluster.APIEndPoint, cluster.Name, randomString(32, passwordChars))
+}
+
+// memorySuccess is the response the Civo API gives to a successful action
+func memorySuccess(id string) *civogo.SimpleResponse {
+	return &civogo.SimpleResponse{ID: id, Result: civogo.ResultSuccess}
+}
+
+// sshFingerprint returns the MD5 fingerprint of an OpenSSH public key
+func sshFingerprint(publicKey string) string {
+	data := []byte(publicKey)
+	if fields := strings.Fields(publicKey); len(fields) > 1 {
+		if decoded, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+			data = decoded
+		}
+	}
+
+	sum := md5.Sum(data)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = hex.EncodeToString([]byte{b})
+	}
+
+	return strings.Join(parts, ":")
+}
+
+const (
+	passwordChars  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	accessKeyChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// randomString returns a random string of the given length made of the given characters
+func randomString(length int, chars string) string {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(chars)))
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		result[i] = chars[n.Int64()]
+	}
+
+	return string(result)
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return formatUUID(b)
+}
+
+// formatUUID formats 16 bytes as a UUID
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
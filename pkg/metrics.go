@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// The gRPC server metrics use the names of go-grpc-prometheus, so the usual dashboards work
+var (
+	grpcServerStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_started_total",
+		Help: "Number of calls started on the server.",
+	}, []string{"grpc_type", "grpc_service", "grpc_method"})
+	grpcServerHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Number of calls completed on the server, by code.",
+	}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"})
+	grpcServerHandlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time taken by the server to answer the calls.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"})
+
+	civoAPIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "opencp_civo_api_requests_total",
+		Help: "Number of Civo API calls, by endpoint, code and the gRPC method that made them.",
+	}, []string{"endpoint", "code", "grpc_service", "grpc_method"})
+	civoAPIRequestSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "opencp_civo_api_request_duration_seconds",
+		Help:    "Time taken by the Civo API calls, by endpoint and the gRPC method that made them.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"endpoint", "grpc_service", "grpc_method"})
+	civoAPIRequestsPerCall = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "opencp_civo_api_requests_per_call",
+		Help:    "Number of Civo API calls made to answer one gRPC call.",
+		Buckets: []float64{0, 1, 2, 3, 5, 8, 13, 21, 34},
+	}, []string{"grpc_service", "grpc_method"})
+)
+
+// MetricsUnaryServerInterceptor records the metrics of the unary calls
+func MetricsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, done := startCallMetrics(ctx, "unary", info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+
+		return resp, err
+	}
+}
+
+// MetricsStreamServerInterceptor records the metrics of the stream calls
+func MetricsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		grpcType := "bidi_stream"
+		switch {
+		case info.IsClientStream && !info.IsServerStream:
+			grpcType = "client_stream"
+		case !info.IsClientStream && info.IsServerStream:
+			grpcType = "server_stream"
+		}
+
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		ctx, done := startCallMetrics(stream.Context(), grpcType, info.FullMethod)
+		wrapped.WrappedContext = ctx
+		err := handler(srv, wrapped)
+		done(err)
+
+		return err
+	}
+}
+
+// callMetrics are the metrics of a gRPC call shared with the Civo API calls it makes
+type callMetrics struct {
+	service   string
+	method    string
+	civoCalls int64
+}
+
+// startCallMetrics records the start of a call and returns the function recording its end
+func startCallMetrics(ctx context.Context, grpcType, fullMethod string) (context.Context, func(error)) {
+	service, method := splitMethod(fullMethod)
+	grpcServerStarted.WithLabelValues(grpcType, service, method).Inc()
+
+	metrics := &callMetrics{service: service, method: method}
+	ctx = context.WithValue(ctx, "callMetrics", metrics)
+	start := time.Now()
+
+	return ctx, func(err error) {
+		code := status.Code(CivoErrorToStatus(err)).String()
+		grpcServerHandled.WithLabelValues(grpcType, service, method, code).Inc()
+		grpcServerHandlingSeconds.WithLabelValues(grpcType, service, method, code).Observe(time.Since(start).Seconds())
+		civoAPIRequestsPerCall.WithLabelValues(service, method).Observe(float64(atomic.LoadInt64(&metrics.civoCalls)))
+	}
+}
+
+// CivoAPIMetrics is the ProviderCallHook recording the metrics of the Civo API calls
+func CivoAPIMetrics() ProviderCallHook {
+	return func(ctx context.Context, endpoint string, next func() error) error {
+		metrics, ok := ctx.Value("callMetrics").(*callMetrics)
+		if !ok {
+			metrics = &callMetrics{}
+		}
+		atomic.AddInt64(&metrics.civoCalls, 1)
+
+		start := time.Now()
+		err := next()
+		civoAPIRequestSeconds.WithLabelValues(endpoint, metrics.service, metrics.method).Observe(time.Since(start).Seconds())
+		civoAPIRequests.WithLabelValues(endpoint, status.Code(CivoErrorToStatus(err)).String(), metrics.service, metrics.method).Inc()
+
+		return err
+	}
+}
+
+// splitMethod splits /package.Service/Method into the service and the method
+func splitMethod(fullMethod string) (string, string) {
+	return strings.TrimPrefix(path.Dir(fullMethod), "/"), path.Base(fullMethod)
+}
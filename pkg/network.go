@@ -2,9 +2,6 @@ package pkg
 
 import (
 	"context"
-	"log"
-
-	"github.com/civo/civogo"
 
 	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
 	corev1 "k8s.io/api/core/v1"
@@ -15,12 +12,13 @@ import (
 // ListNamespace returns a list of all the namespaces
 func (s *Server) ListNamespace(ctx context.Context, in *opencpspec.FilterOptions) (*opencpspec.NamespaceList, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all the networks again and return them
 	allNetwork, err := client.ListNetworks()
 	if err != nil {
-		log.Println(err)
+		Logger(ctx).WithError(err).Error("failed to list the networks")
+		return nil, err
 	}
 
 	// Convert the networks to the opencp format
@@ -53,25 +51,27 @@ func (s *Server) ListNamespace(ctx context.Context, in *opencpspec.FilterOptions
 // CreateNamespace creates a new namespace
 func (s *Server) CreateNamespace(ctx context.Context, in *opencpspec.Namespace) (*opencpspec.Namespace, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Create the network
 	networkResult, err := client.NewNetwork(in.Metadata.Name)
 	if err != nil {
-		log.Println(err)
+		Logger(ctx).WithError(err).Error("failed to create the network")
+		return nil, err
 	}
 
 	// Get the network
 	network, err := s.GetNamespace(ctx, &opencpspec.FilterOptions{Name: &networkResult.Label})
 	if err != nil {
-		log.Println(err)
+		Logger(ctx).WithError(err).Error("failed to get the created network")
+		return nil, err
 	}
 
 	return network, nil
 }
 
 func (s *Server) GetNamespace(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Namespace, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// check the options to see wish value to use
 	// TODO do a better check, put this in a util function
@@ -86,7 +86,8 @@ func (s *Server) GetNamespace(ctx context.Context, option *opencpspec.FilterOpti
 	// Get all the networks again and return them
 	network, err := client.FindNetwork(filter)
 	if err != nil {
-		log.Println(err)
+		Logger(ctx).WithError(err).Error("failed to find the network")
+		return nil, err
 	}
 
 	// Convert the networks to the opencp format
@@ -112,19 +113,22 @@ func (s *Server) GetNamespace(ctx context.Context, option *opencpspec.FilterOpti
 }
 
 func (s *Server) DeleteNamespace(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.Namespace, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all the networks again and return them
 	network, err := s.GetNamespace(ctx, option)
+	if isDryRun(ctx) {
+		return network, err
+	}
 	if err != nil {
-		log.Println(err)
+		Logger(ctx).WithError(err).Error("failed to get the network to delete")
+		return nil, err
 	}
 
-	if network != nil {
-		_, err = client.DeleteNetwork(string(network.Metadata.UID))
-		if err != nil {
-			log.Println(err)
-		}
+	_, err = client.DeleteNetwork(string(network.Metadata.UID))
+	if err != nil {
+		Logger(ctx).WithError(err).Error("failed to delete the network")
+		return nil, err
 	}
 
 	// Return the list of networks
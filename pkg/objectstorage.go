@@ -12,14 +12,14 @@ import (
 )
 
 func (s *Server) CreateObjectStorage(ctx context.Context, in *opencpspec.ObjectStorage) (*opencpspec.ObjectStorage, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Create Object Storage config
 	objectStorageConfig := &civogo.CreateObjectStoreRequest{
 		Name:        in.Metadata.Name,
 		MaxSizeGB:   int64(in.Spec.Size),
 		AccessKeyID: in.Spec.StorageCredential,
-		Region:      client.Region,
+		Region:      client.GetRegion(),
 	}
 
 	// Create object storage
@@ -38,7 +38,7 @@ func (s *Server) CreateObjectStorage(ctx context.Context, in *opencpspec.ObjectS
 }
 
 func (s *Server) DeleteObjectStorage(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.ObjectStorage, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get object storage
 	objectStorage, err := s.GetObjectStorage(ctx, option)
@@ -46,6 +46,10 @@ func (s *Server) DeleteObjectStorage(ctx context.Context, option *opencpspec.Fil
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return objectStorage, nil
+	}
+
 	if objectStorage != nil {
 		// Delete object storage
 		_, err = client.DeleteObjectStore(string(objectStorage.Metadata.UID))
@@ -57,7 +61,7 @@ func (s *Server) DeleteObjectStorage(ctx context.Context, option *opencpspec.Fil
 }
 
 func (s *Server) GetObjectStorage(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.ObjectStorage, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get object storage
 	objectStorage, err := client.FindObjectStore(*option.Name)
@@ -84,7 +88,7 @@ func (s *Server) GetObjectStorage(ctx context.Context, option *opencpspec.Filter
 }
 
 func (s *Server) ListObjectStorage(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.ObjectStorageList, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// List all object storage
 	objectStorages, err := client.ListObjectStores()
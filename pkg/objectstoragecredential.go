@@ -10,12 +10,12 @@ import (
 )
 
 func (s *Server) CreateObjectStorageCredential(ctx context.Context, in *opencpspec.ObjectStorageCredential) (*opencpspec.ObjectStorageCredential, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Create Object Storage config
 	objectStorageCredentialConfig := &civogo.CreateObjectStoreCredentialRequest{
 		Name:              in.Metadata.Name,
-		Region:            client.Region,
+		Region:            client.GetRegion(),
 	}
 
 	// Check if the access key is provided
@@ -44,7 +44,7 @@ func (s *Server) CreateObjectStorageCredential(ctx context.Context, in *opencpsp
 }
 
 func (s *Server) DeleteObjectStorageCredential(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.ObjectStorageCredential, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get object storage
 	objectStorageCredential, err := s.GetObjectStorageCredential(ctx, option)
@@ -52,9 +52,13 @@ func (s *Server) DeleteObjectStorageCredential(ctx context.Context, option *open
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return objectStorageCredential, nil
+	}
+
 	if objectStorageCredential != nil {
-		// Delete object storage
-		_, err = client.DeleteObjectStore(string(objectStorageCredential.Metadata.UID))
+		// Delete the object storage credential
+		_, err = client.DeleteObjectStoreCredential(string(objectStorageCredential.Metadata.UID))
 		if err != nil {
 			return nil, err
 		}
@@ -63,7 +67,7 @@ func (s *Server) DeleteObjectStorageCredential(ctx context.Context, option *open
 }
 
 func (s *Server) GetObjectStorageCredential(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.ObjectStorageCredential, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get object storage
 	objectStorageCredential, err := client.FindObjectStoreCredential(*option.Name)
@@ -88,7 +92,7 @@ func (s *Server) GetObjectStorageCredential(ctx context.Context, option *opencps
 }
 
 func (s *Server) ListObjectStorageCredential(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.ObjectStorageCredentialList, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// List all object storage
 	objectStoragesCredential, err := client.ListObjectStoreCredentials()
@@ -0,0 +1,399 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// policyReloadInterval is how often the policy file is checked for changes
+const policyReloadInterval = 10 * time.Second
+
+var authorizationDenied = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "opencp_authorization_denied_total",
+	Help: "Number of calls denied by the authorization policy.",
+}, []string{"grpc_service", "grpc_method"})
+
+// verbRegexp finds the verb at the start of a method name, such as List in ListVirtualMachine
+// or SoftReboot in SoftRebootVirtualMachine
+var verbRegexp = regexp.MustCompile(`^((Soft|Hard)Reboot|[A-Z][a-z]*)`)
+
+// revealVerb is the verb of the actions letting the callers see the secrets of a service in clear.
+// Only the actions naming it allow it, * and Service/* do not
+const revealVerb = "Reveal"
+
+// Policy is the authorization policy: roles of rules, bound to the callers
+type Policy struct {
+	Roles    []PolicyRole    `yaml:"roles"`
+	Bindings []PolicyBinding `yaml:"bindings"`
+}
+
+// PolicyRole is a named set of rules
+type PolicyRole struct {
+	Name  string       `yaml:"name"`
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyRule allows or denies actions such as DatabaseService/Delete, or DatabaseService/* and */List.
+// With namespaces the rule only allows the calls in these namespaces, and denies the calls in them
+// as well as the calls with no namespace, which could reach them
+type PolicyRule struct {
+	Effect     string   `yaml:"effect"`
+	Actions    []string `yaml:"actions"`
+	Namespaces []string `yaml:"namespaces"`
+}
+
+// PolicyBinding gives a role to the callers with one of the token hashes, Civo accounts or mTLS subjects
+type PolicyBinding struct {
+	Role           string   `yaml:"role"`
+	TokenHashes    []string `yaml:"tokenHashes"`
+	Accounts       []string `yaml:"accounts"`
+	ClientSubjects []string `yaml:"clientSubjects"`
+}
+
+// validate returns the problems of the policy
+func (p *Policy) validate() []string {
+	problems := []string{}
+
+	roles := map[string]bool{}
+	for _, role := range p.Roles {
+		if role.Name == "" || roles[role.Name] {
+			problems = append(problems, fmt.Sprintf("role %q must have a unique name", role.Name))
+		}
+		roles[role.Name] = true
+
+		for i, rule := range role.Rules {
+			if rule.Effect != "allow" && rule.Effect != "deny" {
+				problems = append(problems, fmt.Sprintf("rule %d of role %s: effect %q must be allow or deny", i, role.Name, rule.Effect))
+			}
+			if len(rule.Actions) == 0 {
+				problems = append(problems, fmt.Sprintf("rule %d of role %s has no action", i, role.Name))
+			}
+			for _, action := range rule.Actions {
+				if action != "*" && strings.Count(action, "/") != 1 {
+					problems = append(problems, fmt.Sprintf("rule %d of role %s: action %q must be a service and a verb such as DatabaseService/Delete", i, role.Name, action))
+				}
+			}
+		}
+	}
+
+	for i, binding := range p.Bindings {
+		if !roles[binding.Role] {
+			problems = append(problems, fmt.Sprintf("binding %d: unknown role %q", i, binding.Role))
+		}
+		for _, hash := range binding.TokenHashes {
+			if _, err := hex.DecodeString(hash); err != nil || len(hash) != 64 {
+				problems = append(problems, fmt.Sprintf("binding %d: token hash %q must be the hex SHA-256 hash of a token", i, hash))
+			}
+		}
+	}
+
+	return problems
+}
+
+// Authorizer checks the calls against the policy of a file, loading it again when the file changes
+// so the policy can be changed without a restart
+type Authorizer struct {
+	file string
+
+	mu      sync.RWMutex
+	policy  *Policy
+	modTime time.Time
+	checked time.Time
+}
+
+// NewAuthorizer loads the policy file, failing if it is not valid. With no file every call is allowed
+func NewAuthorizer(file string) (*Authorizer, error) {
+	a := &Authorizer{file: file}
+	if file == "" {
+		return a, nil
+	}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// UnaryServerInterceptor rejects the unary calls the policy does not allow with PermissionDenied
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := a.authorize(ctx, info.FullMethod, requestNamespace(info.FullMethod, req)); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the stream calls the policy does not allow, they have no namespace
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authorize(stream.Context(), info.FullMethod, ""); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// authorize returns nil if a rule of the roles of the caller allows the method in the namespace
+// and none denies it. Only the OpenCP services and the Civo services using a token are checked,
+// and Login/Check is always allowed
+func (a *Authorizer) authorize(ctx context.Context, fullMethod, namespace string) error {
+	service, method := splitMethod(fullMethod)
+	shortService, ok := policyService(service)
+	if a.file == "" || !ok || strings.HasSuffix(fullMethod, "Login/Check") {
+		return nil
+	}
+
+	return a.check(ctx, fullMethod, shortService, verbRegexp.FindString(method), namespace)
+}
+
+// RevealSecrets is the RevealPolicy letting the caller see the secrets answered by the method when
+// a role of the caller allows the Reveal verb of its service, such as DatabaseService/Reveal, in the
+// namespace of the call. With no policy file every caller may see them
+func (a *Authorizer) RevealSecrets(ctx context.Context, fullMethod string, req interface{}) error {
+	service, _ := splitMethod(fullMethod)
+	shortService, ok := policyService(service)
+	if a.file == "" || !ok {
+		return nil
+	}
+
+	return a.check(ctx, fullMethod, shortService, revealVerb, requestNamespace(fullMethod, req))
+}
+
+// check returns nil if a rule of the roles of the caller allows the verb of the service in the
+// namespace and none denies it
+func (a *Authorizer) check(ctx context.Context, fullMethod, shortService, verb, namespace string) error {
+	service, method := splitMethod(fullMethod)
+
+	a.reload()
+	a.mu.RLock()
+	policy := a.policy
+	a.mu.RUnlock()
+
+	allowed := false
+	for _, role := range policy.callerRoles(ctx) {
+		for _, rule := range role.Rules {
+			if !rule.matchesAction(shortService, verb) {
+				continue
+			}
+
+			if rule.Effect == "deny" && rule.deniesNamespace(namespace) {
+				return a.deny(ctx, service, method, fmt.Sprintf("%s/%s is denied by role %s", shortService, verb, role.Name))
+			}
+			if rule.Effect == "allow" && rule.allowsNamespace(namespace) {
+				allowed = true
+			}
+		}
+	}
+
+	if !allowed {
+		if namespace == "" {
+			return a.deny(ctx, service, method, fmt.Sprintf("%s/%s is not allowed", shortService, verb))
+		}
+		return a.deny(ctx, service, method, fmt.Sprintf("%s/%s is not allowed in namespace %s", shortService, verb, namespace))
+	}
+
+	return nil
+}
+
+// deny counts and logs a denied call, and returns its error
+func (a *Authorizer) deny(ctx context.Context, service, method, reason string) error {
+	authorizationDenied.WithLabelValues(service, method).Inc()
+	Logger(ctx).WithField("grpc.method", service+"/"+method).Warn("call denied by the authorization policy: " + reason)
+
+	return status.Error(codes.PermissionDenied, reason)
+}
+
+// callerRoles returns the roles bound to the token, the account or the mTLS subject of the call
+func (p *Policy) callerRoles(ctx context.Context) []PolicyRole {
+	tokenHash := CallerTokenHash(ctx)
+	subject := ClientSubject(ctx)
+	account := ""
+	if client, ok := ctx.Value("client").(Provider); ok {
+		account = client.GetAccountID()
+	}
+
+	bound := map[string]bool{}
+	for _, binding := range p.Bindings {
+		if (tokenHash != "" && contains(binding.TokenHashes, tokenHash)) ||
+			(account != "" && contains(binding.Accounts, account)) ||
+			(subject != "" && contains(binding.ClientSubjects, subject)) {
+			bound[binding.Role] = true
+		}
+	}
+
+	roles := []PolicyRole{}
+	for _, role := range p.Roles {
+		if bound[role.Name] {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// policyService returns the name of a service in the actions, without its package, and false for
+// the services that can be called without a token
+func policyService(service string) (string, bool) {
+	switch {
+	case strings.HasPrefix(service, "opencp."):
+		return strings.TrimPrefix(service, "opencp."), true
+	case service == "civo.opencp.v1.InfoService":
+		return "", false
+	case strings.HasPrefix(service, "civo.opencp.v1."):
+		return strings.TrimPrefix(service, "civo.opencp.v1."), true
+	}
+
+	return "", false
+}
+
+// matchesAction returns true if one of the actions of the rule is the service and verb,
+// the wildcard verbs match every verb but Reveal
+func (r PolicyRule) matchesAction(service, verb string) bool {
+	for _, action := range r.Actions {
+		if action == "*" && verb != revealVerb {
+			return true
+		}
+
+		actionService, actionVerb, _ := strings.Cut(action, "/")
+		if name, ok := policyService(actionService); ok {
+			actionService = name
+		}
+		if (actionService == "*" || actionService == service) && (actionVerb == verb || (actionVerb == "*" && verb != revealVerb)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsNamespace returns true if an allow rule covers the namespace, a call with no namespace
+// is only allowed by the rules with no namespaces
+func (r PolicyRule) allowsNamespace(namespace string) bool {
+	if len(r.Namespaces) == 0 || contains(r.Namespaces, "*") {
+		return true
+	}
+
+	return namespace != "" && contains(r.Namespaces, namespace)
+}
+
+// deniesNamespace returns true if a deny rule covers the namespace, a call with no namespace
+// could reach any namespace so it is denied by every rule
+func (r PolicyRule) deniesNamespace(namespace string) bool {
+	if namespace == "" || len(r.Namespaces) == 0 || contains(r.Namespaces, "*") {
+		return true
+	}
+
+	return contains(r.Namespaces, namespace)
+}
+
+// requestNamespace returns the namespace a call is about: the namespace of its filter or of its
+// resource, or the name of the namespace for the NamespaceService
+func requestNamespace(fullMethod string, req interface{}) string {
+	service, _ := splitMethod(fullMethod)
+	isNamespaceService := service == "opencp.NamespaceService"
+
+	if filter, ok := req.(*opencpspec.FilterOptions); ok && filter != nil {
+		if isNamespaceService && filter.Name != nil {
+			return *filter.Name
+		}
+		if filter.Namespace != nil {
+			return *filter.Namespace
+		}
+		return ""
+	}
+
+	if meta := objectMeta(req); meta != nil {
+		if isNamespaceService {
+			return meta.Name
+		}
+		return meta.Namespace
+	}
+
+	return ""
+}
+
+// contains returns true if the value is one of the items
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reload loads the policy file again if it changed, keeping the current policy when the new one is not valid
+func (a *Authorizer) reload() {
+	a.mu.RLock()
+	due := time.Since(a.checked) >= policyReloadInterval
+	modTime := a.modTime
+	a.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	a.mu.Lock()
+	a.checked = time.Now()
+	a.mu.Unlock()
+
+	info, err := os.Stat(a.file)
+	if err != nil || info.ModTime().Equal(modTime) {
+		return
+	}
+
+	if err := a.load(); err != nil {
+		logrus.WithError(err).Error("failed to reload the authorization policy, still using the previous one")
+		return
+	}
+
+	logrus.WithField("file", a.file).Info("reloaded the authorization policy")
+}
+
+// load reads and validates the policy file
+func (a *Authorizer) load() error {
+	info, err := os.Stat(a.file)
+	if err != nil {
+		return fmt.Errorf("reading the authorization policy: %w", err)
+	}
+
+	data, err := os.ReadFile(a.file)
+	if err != nil {
+		return fmt.Errorf("reading the authorization policy: %w", err)
+	}
+
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return fmt.Errorf("parsing the authorization policy %s: %w", a.file, err)
+	}
+	if problems := policy.validate(); len(problems) > 0 {
+		return fmt.Errorf("invalid authorization policy %s: %s", a.file, strings.Join(problems, "; "))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.policy = policy
+	a.modTime = info.ModTime()
+	a.checked = time.Now()
+
+	return nil
+}
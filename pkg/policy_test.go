@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `
+roles:
+- name: developer
+  rules:
+  - effect: allow
+    actions: ["*"]
+    namespaces: [dev]
+  - effect: allow
+    actions: ["*/List", "DatabaseService/Reveal"]
+  - effect: deny
+    actions: ["VirtualMachineService/Delete"]
+- name: operator
+  rules:
+  - effect: allow
+    actions: ["*"]
+bindings:
+- role: developer
+  tokenHashes: [%s]
+- role: operator
+  tokenHashes: [%s]
+`
+
+func TestAuthorizer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policy := []byte(fmt.Sprintf(testPolicy, TokenHash("developer"), TokenHash("operator")))
+	if err := os.WriteFile(path, policy, 0o600); err != nil {
+		t.Fatalf("writing the policy: %v", err)
+	}
+	authorizer, err := NewAuthorizer(path)
+	if err != nil {
+		t.Fatalf("loading the policy: %v", err)
+	}
+
+	tests := []struct {
+		token, method, namespace string
+		allowed                  bool
+	}{
+		{"developer", "/opencp.VirtualMachineService/CreateVirtualMachine", "dev", true},
+		{"developer", "/opencp.VirtualMachineService/CreateVirtualMachine", "prod", false},
+		{"developer", "/opencp.VirtualMachineService/ListVirtualMachine", "prod", true},
+		{"developer", "/opencp.VirtualMachineService/ListVirtualMachine", "", true},
+		{"developer", "/opencp.DomainService/CreateDomain", "", false},
+		{"developer", "/opencp.VirtualMachineService/DeleteVirtualMachine", "dev", false},
+		{"developer", "/civo.opencp.v1.PowerService/StopVirtualMachine", "dev", true},
+		{"developer", "/civo.opencp.v1.PowerService/StopVirtualMachine", "prod", false},
+		{"developer", "/civo.opencp.v1.InfoService/GetInfo", "", true},
+		{"developer", "/opencp.Login/Check", "", true},
+		{"operator", "/opencp.DomainService/DeleteDomain", "", true},
+		{"nobody", "/opencp.VirtualMachineService/ListVirtualMachine", "dev", false},
+	}
+	for _, test := range tests {
+		err := authorizer.authorize(callerContext(test.token), test.method, test.namespace)
+		if allowed := err == nil; allowed != test.allowed {
+			t.Errorf("%s calling %s in %q: got %v, want allowed %v", test.token, test.method, test.namespace, err, test.allowed)
+		}
+		if err != nil && status.Code(err) != codes.PermissionDenied {
+			t.Errorf("%s calling %s in %q: got %v, want PermissionDenied", test.token, test.method, test.namespace, err)
+		}
+	}
+
+	// Reveal has to be named, the * actions don't allow it
+	reveals := []struct {
+		token, method string
+		allowed       bool
+	}{
+		{"developer", "/opencp.DatabaseService/GetDatabase", true},
+		{"developer", "/opencp.ObjectStorageCredentialService/GetObjectStorageCredential", false},
+		{"operator", "/opencp.DatabaseService/GetDatabase", false},
+	}
+	for _, reveal := range reveals {
+		err := authorizer.RevealSecrets(callerContext(reveal.token), reveal.method, nil)
+		if allowed := err == nil; allowed != reveal.allowed {
+			t.Errorf("%s revealing the secrets of %s: got %v, want allowed %v", reveal.token, reveal.method, err, reveal.allowed)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	policy := &Policy{
+		Roles: []PolicyRole{
+			{Name: "reader", Rules: []PolicyRule{{Effect: "permit", Actions: []string{"DatabaseService"}}}},
+			{Name: "reader"},
+		},
+		Bindings: []PolicyBinding{{Role: "writer"}},
+	}
+
+	if problems := policy.validate(); len(problems) < 4 {
+		t.Fatalf("got the problems %v, want the effect, the action, the duplicate role and the unknown role", problems)
+	}
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"context"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PowerStateAnnotation is the annotation of a virtual machine with its power state. Set on an update
+// it starts, stops or reboots the virtual machine
+const PowerStateAnnotation = "opencp.io/power-state"
+
+// The power states of the annotation, the reboots are actions run on every update setting them
+const (
+	PowerStateRunning    = "running"
+	PowerStateStopped    = "stopped"
+	PowerStateSoftReboot = "soft-reboot"
+	PowerStateHardReboot = "hard-reboot"
+)
+
+// Civo status of the running and the stopped instances
+const (
+	instanceActive  = "ACTIVE"
+	instanceShutOff = "SHUTOFF"
+)
+
+// StartVirtualMachine starts a stopped virtual machine
+func (s *Server) StartVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachine, error) {
+	return s.setPowerState(ctx, option, PowerStateRunning)
+}
+
+// StopVirtualMachine shuts a virtual machine down
+func (s *Server) StopVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachine, error) {
+	return s.setPowerState(ctx, option, PowerStateStopped)
+}
+
+// SoftRebootVirtualMachine asks the operating system of a virtual machine to reboot
+func (s *Server) SoftRebootVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachine, error) {
+	return s.setPowerState(ctx, option, PowerStateSoftReboot)
+}
+
+// HardRebootVirtualMachine power cycles a virtual machine
+func (s *Server) HardRebootVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachine, error) {
+	return s.setPowerState(ctx, option, PowerStateHardReboot)
+}
+
+// setPowerState gets the virtual machine of the options to the power state, and returns it
+func (s *Server) setPowerState(ctx context.Context, option *opencpspec.FilterOptions, state string) (*opencpspec.VirtualMachine, error) {
+	client := ctx.Value("client").(Provider)
+
+	if option == nil || (option.Name == nil && option.Id == nil) {
+		return nil, invalidArgument("the name or the ID of the virtual machine is required")
+	}
+
+	virtualMachine, err := s.GetVirtualMachine(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPowerState(virtualMachine, state); err != nil {
+		return nil, err
+	}
+
+	if isDryRun(ctx) {
+		return virtualMachine, nil
+	}
+
+	if err := applyPowerState(client, virtualMachine, state); err != nil {
+		return nil, err
+	}
+
+	return s.GetVirtualMachine(ctx, option)
+}
+
+// checkPowerState checks that the virtual machine can get to the power state
+func checkPowerState(vm *opencpspec.VirtualMachine, state string) error {
+	switch state {
+	case PowerStateRunning, PowerStateStopped:
+	case PowerStateSoftReboot, PowerStateHardReboot:
+		if vm.Status.State == instanceShutOff {
+			return status.Errorf(codes.FailedPrecondition, "virtual machine %q is stopped, start it instead of rebooting it", vm.Metadata.Name)
+		}
+	default:
+		return invalidArgument("%s %q of virtual machine %q must be %s, %s, %s or %s", PowerStateAnnotation, state, vm.Metadata.Name,
+			PowerStateRunning, PowerStateStopped, PowerStateSoftReboot, PowerStateHardReboot)
+	}
+
+	return nil
+}
+
+// applyPowerState starts, stops or reboots the virtual machine, nothing is done when it is already running or stopped
+func applyPowerState(client Provider, vm *opencpspec.VirtualMachine, state string) error {
+	id := string(vm.Metadata.UID)
+
+	var err error
+	switch state {
+	case PowerStateRunning:
+		if vm.Status.State != instanceActive {
+			_, err = client.StartInstance(id)
+		}
+	case PowerStateStopped:
+		if vm.Status.State != instanceShutOff {
+			_, err = client.StopInstance(id)
+		}
+	case PowerStateSoftReboot:
+		_, err = client.SoftRebootInstance(id)
+	case PowerStateHardReboot:
+		_, err = client.HardRebootInstance(id)
+	}
+
+	return err
+}
+
+// powerState returns the power state of an instance with the Civo status, empty while it is changing
+func powerState(instanceStatus string) string {
+	switch instanceStatus {
+	case instanceActive:
+		return PowerStateRunning
+	case instanceShutOff:
+		return PowerStateStopped
+	}
+
+	return ""
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"testing"
+
+	civov1 "github.com/civo/civo-opencp/api/civo/v1"
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPowerService(t *testing.T) {
+	conn := newTestConn(t)
+	ctx := withToken("power-token")
+	virtualMachines := opencpspec.NewVirtualMachineServiceClient(conn)
+	power := civov1.NewPowerServiceClient(conn)
+
+	vm := createTestVirtualMachine(t, ctx, virtualMachines, "vm1")
+	id := string(vm.Metadata.UID)
+	option := &opencpspec.FilterOptions{Id: &id}
+
+	stopped, err := power.StopVirtualMachine(ctx, option)
+	if err != nil {
+		t.Fatalf("stopping the virtual machine: %v", err)
+	}
+	if state := stopped.Metadata.Annotations[PowerStateAnnotation]; state != PowerStateStopped {
+		t.Fatalf("stopped virtual machine has the power state %q", state)
+	}
+
+	if _, err := power.SoftRebootVirtualMachine(ctx, option); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("rebooting the stopped virtual machine: got %v, want FailedPrecondition", err)
+	}
+
+	started, err := power.StartVirtualMachine(ctx, option)
+	if err != nil {
+		t.Fatalf("starting the virtual machine: %v", err)
+	}
+	if state := started.Metadata.Annotations[PowerStateAnnotation]; state != PowerStateRunning {
+		t.Fatalf("started virtual machine has the power state %q", state)
+	}
+
+	if _, err := power.HardRebootVirtualMachine(ctx, option); err != nil {
+		t.Fatalf("rebooting the running virtual machine: %v", err)
+	}
+
+	updated, err := virtualMachines.UpdateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{UID: vm.Metadata.UID, Annotations: map[string]string{PowerStateAnnotation: PowerStateStopped}},
+	})
+	if err != nil {
+		t.Fatalf("stopping the virtual machine with an update: %v", err)
+	}
+	if state := updated.Metadata.Annotations[PowerStateAnnotation]; state != PowerStateStopped {
+		t.Fatalf("virtual machine updated to stopped has the power state %q", state)
+	}
+
+	missing := "missing"
+	if _, err := power.StartVirtualMachine(ctx, &opencpspec.FilterOptions{Name: &missing}); status.Code(err) != codes.NotFound {
+		t.Fatalf("starting a missing virtual machine: got %v, want NotFound", err)
+	}
+}
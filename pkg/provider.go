@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/civo/civogo"
+)
+
+// Provider is the set of Civo API calls used by the handlers, it is implemented
+// by the real Civo API client and by the in-memory backend
+type Provider interface {
+	// Account
+	GetRegion() string
+	GetAccountID() string
+	ListAccounts() (*civogo.PaginatedAccounts, error)
+	ListRegions() ([]civogo.Region, error)
+
+	// Instances
+	ListAllInstances() ([]civogo.Instance, error)
+	FindInstance(search string) (*civogo.Instance, error)
+	CreateInstance(config *civogo.InstanceConfig) (*civogo.Instance, error)
+	DeleteInstance(id string) (*civogo.SimpleResponse, error)
+	UpgradeInstance(id, newSize string) (*civogo.SimpleResponse, error)
+	SetInstanceTags(i *civogo.Instance, tags string) (*civogo.SimpleResponse, error)
+	SetInstanceFirewall(id, firewallID string) (*civogo.SimpleResponse, error)
+	RebuildInstance(id, templateID string) (*civogo.SimpleResponse, error)
+	StartInstance(id string) (*civogo.SimpleResponse, error)
+	StopInstance(id string) (*civogo.SimpleResponse, error)
+	SoftRebootInstance(id string) (*civogo.SimpleResponse, error)
+	HardRebootInstance(id string) (*civogo.SimpleResponse, error)
+
+	// Disk images and sizes
+	ListDiskImages() ([]civogo.DiskImage, error)
+	ListInstanceSizes() ([]civogo.InstanceSize, error)
+
+	// Networks
+	ListNetworks() ([]civogo.Network, error)
+	FindNetwork(search string) (*civogo.Network, error)
+	NewNetwork(label string) (*civogo.NetworkResult, error)
+	DeleteNetwork(id string) (*civogo.SimpleResponse, error)
+
+	// Firewalls
+	ListFirewalls() ([]civogo.Firewall, error)
+	FindFirewall(search string) (*civogo.Firewall, error)
+	NewFirewall(config *civogo.FirewallConfig) (*civogo.FirewallResult, error)
+	DeleteFirewall(id string) (*civogo.SimpleResponse, error)
+
+	// DNS
+	ListDNSDomains() ([]civogo.DNSDomain, error)
+	FindDNSDomain(search string) (*civogo.DNSDomain, error)
+	CreateDNSDomain(name string) (*civogo.DNSDomain, error)
+	DeleteDNSDomain(d *civogo.DNSDomain) (*civogo.SimpleResponse, error)
+	ListDNSRecords(dnsDomainID string) ([]civogo.DNSRecord, error)
+	CreateDNSRecord(domainID string, r *civogo.DNSRecordConfig) (*civogo.DNSRecord, error)
+
+	// SSH keys
+	ListSSHKeys() ([]civogo.SSHKey, error)
+	FindSSHKey(search string) (*civogo.SSHKey, error)
+	NewSSHKey(name string, publicKey string) (*civogo.SimpleResponse, error)
+	DeleteSSHKey(id string) (*civogo.SimpleResponse, error)
+
+	// Reserved IPs
+	ListIPs() (*civogo.PaginatedIPs, error)
+	FindIP(search string) (*civogo.IP, error)
+	NewIP(v *civogo.CreateIPRequest) (*civogo.IP, error)
+	DeleteIP(id string) (*civogo.SimpleResponse, error)
+
+	// Databases
+	ListDatabases() (*civogo.PaginatedDatabases, error)
+	FindDatabase(search string) (*civogo.Database, error)
+	NewDatabase(v *civogo.CreateDatabaseRequest) (*civogo.Database, error)
+	DeleteDatabase(id string) (*civogo.SimpleResponse, error)
+
+	// Kubernetes clusters
+	ListKubernetesClusters() (*civogo.PaginatedKubernetesClusters, error)
+	FindKubernetesCluster(search string) (*civogo.KubernetesCluster, error)
+	NewKubernetesClusters(kc *civogo.KubernetesClusterConfig) (*civogo.KubernetesCluster, error)
+	ListAvailableKubernetesVersions() ([]civogo.KubernetesVersion, error)
+	DeleteKubernetesCluster(id string) (*civogo.SimpleResponse, error)
+
+	// Object stores
+	ListObjectStores() (*civogo.PaginatedObjectstores, error)
+	FindObjectStore(search string) (*civogo.ObjectStore, error)
+	NewObjectStore(v *civogo.CreateObjectStoreRequest) (*civogo.ObjectStore, error)
+	DeleteObjectStore(id string) (*civogo.SimpleResponse, error)
+
+	// Object store credentials
+	ListObjectStoreCredentials() (*civogo.PaginatedObjectStoreCredentials, error)
+	FindObjectStoreCredential(search string) (*civogo.ObjectStoreCredential, error)
+	NewObjectStoreCredential(v *civogo.CreateObjectStoreCredentialRequest) (*civogo.ObjectStoreCredential, error)
+	DeleteObjectStoreCredential(id string) (*civogo.SimpleResponse, error)
+}
+
+// ProviderFactory creates the Provider used to serve the calls made with a Civo API token
+type ProviderFactory func(token, region string) (Provider, error)
+
+// civoProvider is the Provider backed by the real Civo API
+type civoProvider struct {
+	*civogo.Client
+}
+
+// GetRegion returns the region the client is working on
+func (c *civoProvider) GetRegion() string {
+	return c.Region
+}
+
+// RebuildInstance reinstalls the instance from the disk image. civogo has no call for it, this is the
+// "Rebuilding an instance" call of the Civo API documentation, https://www.civo.com/api/instances
+func (c *civoProvider) RebuildInstance(id, templateID string) (*civogo.SimpleResponse, error) {
+	resp, err := c.SendPutRequest(fmt.Sprintf("/v2/instances/%s/rebuild", id), map[string]string{
+		"template_id": templateID,
+		"region":      c.Region,
+	})
+	if err != nil {
+		return nil, decodeCivoError(err)
+	}
+
+	return c.DecodeSimpleResponse(resp)
+}
+
+// civoAPIErrors are the civogo errors of the codes the Civo API can answer a rebuild with
+var civoAPIErrors = map[string]error{
+	"authentication_failed":         civogo.AuthenticationFailedError,
+	"authentication_invalid_key":    civogo.AuthenticationInvalidKeyError,
+	"database_template_not_found":   civogo.DatabaseTemplateNotFoundError,
+	"database_disk_image_not_found": civogo.DatabaseDiskImageNotFoundError,
+	"openstack_instance_rebuild":    civogo.OpenstackInstanceRebuildError,
+}
+
+// decodeCivoError turns the HTTP errors of the calls civogo has no method for into the errors civogo
+// returns for its own calls, so they are translated and retried the same way
+func decodeCivoError(err error) error {
+	var httpErr civogo.HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	response := struct {
+		Code    string  `json:"code"`
+		Reason  string  `json:"reason"`
+		Result  string  `json:"result"`
+		Status  float64 `json:"status"`
+		Details string  `json:"details"`
+	}{}
+	if json.Unmarshal([]byte(httpErr.Reason), &response) != nil {
+		return fmt.Errorf("%w: failed to decode the response expected from the API - status: %s, code: %d, reason: %s", civogo.ResponseDecodeFailedError, httpErr.Status, httpErr.Code, httpErr.Reason)
+	}
+
+	reason := response.Reason
+	if response.Details != "" {
+		reason += ", " + response.Details
+	}
+
+	switch {
+	case response.Status == 500:
+		return fmt.Errorf("%w: internal Server Error", civogo.InternalServerError)
+	case response.Result == "requires_authentication":
+		return fmt.Errorf("%w: authentication Error", civogo.AuthenticationError)
+	case civoAPIErrors[response.Code] != nil:
+		return fmt.Errorf("%w: %s", civoAPIErrors[response.Code], reason)
+	}
+
+	return fmt.Errorf("%w: Unknown error response - status: %s, code: %d, reason: %s", civogo.CommonError, httpErr.Status, httpErr.Code, httpErr.Reason)
+}
+
+// CivoAPIURL is the URL of the production Civo API
+const CivoAPIURL = "https://api.civo.com"
+
+// NewCivoProvider creates a Provider that talks to the production Civo API
+func NewCivoProvider(token, region string) (Provider, error) {
+	return NewCivoProviderFactory(CivoAPIURL)(token, region)
+}
+
+// NewCivoProviderFactory returns a ProviderFactory for the Civo API at apiURL
+func NewCivoProviderFactory(apiURL string) ProviderFactory {
+	return func(token, region string) (Provider, error) {
+		client, err := civogo.NewClientWithURL(token, apiURL, region)
+		if err != nil {
+			return nil, err
+		}
+
+		client.SetUserAgent(&civogo.Component{
+			Name:    "opencp.io",
+			Version: Version,
+		})
+
+		return &civoProvider{Client: client}, nil
+	}
+}
@@ -0,0 +1,274 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryAfterMetadataKey is the trailer telling a limited caller how many seconds to wait before retrying
+const RetryAfterMetadataKey = "retry-after"
+
+// rateLimitIdle is how long the limits of a caller are kept after its last call
+const rateLimitIdle = 10 * time.Minute
+
+var (
+	rateLimitRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "opencp_rate_limit_rejected_total",
+		Help: "Number of calls rejected by the rate limits, by reason, rate or concurrency.",
+	}, []string{"grpc_service", "grpc_method", "reason"})
+	rateLimitCallers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "opencp_rate_limit_callers",
+		Help: "Number of callers whose limits are tracked, one per caller and limit.",
+	})
+	rateLimitRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "opencp_rate_limit_running_calls",
+		Help: "Number of calls running under a concurrency limit, by limit.",
+	}, []string{"limit"})
+)
+
+// RateLimitConfig is how the calls of each caller are limited
+type RateLimitConfig struct {
+	// Key identifies the callers, token for the hash of their Civo API token or account for their Civo account
+	Key string `yaml:"key"`
+
+	// Default is the limit of the methods that have none in Methods
+	Default RateLimit `yaml:"default"`
+
+	// Methods are the limits of a service such as opencp.KubernetesClusterService, or of a method such as
+	// opencp.KubernetesClusterService/ListKubernetesCluster. A method uses its own limit, then its service's
+	Methods map[string]RateLimit `yaml:"methods"`
+}
+
+// RateLimit is a token bucket refilled with Rate calls per second that holds up to Burst calls,
+// and a cap of Concurrency calls running at once. Zero turns the limit off
+type RateLimit struct {
+	Rate        float64 `yaml:"rate"`
+	Burst       int     `yaml:"burst"`
+	Concurrency int     `yaml:"concurrency"`
+}
+
+// enabled returns true if the limit limits anything
+func (l RateLimit) enabled() bool {
+	return l.Rate > 0 || l.Concurrency > 0
+}
+
+// burst returns the size of the bucket, at least one second of calls when it is not set
+func (l RateLimit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return int(math.Max(1, math.Ceil(l.Rate)))
+}
+
+// validate returns the problems of the rate limit settings
+func (c *RateLimitConfig) validate() []string {
+	problems := []string{}
+
+	if c.Key != "token" && c.Key != "account" {
+		problems = append(problems, fmt.Sprintf("rate limit key %q must be token or account", c.Key))
+	}
+
+	limits := map[string]RateLimit{"default": c.Default}
+	for name, limit := range c.Methods {
+		if name == "" || strings.HasPrefix(name, "/") || strings.Count(name, "/") > 1 {
+			problems = append(problems, fmt.Sprintf("rate limit %q must be a service such as opencp.VirtualMachineService or a method such as opencp.VirtualMachineService/ListVirtualMachine", name))
+		}
+		limits[name] = limit
+	}
+	for name, limit := range limits {
+		if limit.Rate < 0 || limit.Burst < 0 || limit.Concurrency < 0 {
+			problems = append(problems, fmt.Sprintf("rate limit %s can't be negative", name))
+		}
+	}
+
+	return problems
+}
+
+// RateLimiter limits the calls of each caller with the limits of the config
+type RateLimiter struct {
+	config RateLimitConfig
+
+	mu        sync.Mutex
+	callers   map[string]*callerLimit
+	lastSweep time.Time
+}
+
+// callerLimit is the state of a limit for one caller
+type callerLimit struct {
+	limiter  *rate.Limiter
+	running  int
+	lastCall time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with no caller yet
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		config:    config,
+		callers:   map[string]*callerLimit{},
+		lastSweep: time.Now(),
+	}
+}
+
+// UnaryServerInterceptor rejects the unary calls over the limits of their caller
+func (l *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		release, retryAfter, err := l.acquire(ctx, info.FullMethod)
+		if err != nil {
+			grpc.SetTrailer(ctx, retryAfterMetadata(retryAfter))
+			return nil, err
+		}
+		defer release()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the stream calls over the limits of their caller
+func (l *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, retryAfter, err := l.acquire(stream.Context(), info.FullMethod)
+		if err != nil {
+			stream.SetTrailer(retryAfterMetadata(retryAfter))
+			return err
+		}
+		defer release()
+
+		return handler(srv, stream)
+	}
+}
+
+// limit returns the limit of a method and the name it is configured under
+func (l *RateLimiter) limit(fullMethod string) (string, RateLimit) {
+	service, method := splitMethod(fullMethod)
+	if limit, ok := l.config.Methods[service+"/"+method]; ok {
+		return service + "/" + method, limit
+	}
+	if limit, ok := l.config.Methods[service]; ok {
+		return service, limit
+	}
+
+	return "default", l.config.Default
+}
+
+// caller returns the key of the caller, empty when the call has no token or no account
+func (l *RateLimiter) caller(ctx context.Context) string {
+	if l.config.Key == "account" {
+		if client, ok := ctx.Value("client").(Provider); ok {
+			return client.GetAccountID()
+		}
+		return ""
+	}
+
+	return CallerTokenHash(ctx)
+}
+
+// acquire starts a call of the caller, returning the function ending it, or how long to wait
+// and the error when the call is over a limit
+func (l *RateLimiter) acquire(ctx context.Context, fullMethod string) (func(), time.Duration, error) {
+	name, limit := l.limit(fullMethod)
+	if !limit.enabled() {
+		return func() {}, 0, nil
+	}
+
+	caller := l.caller(ctx)
+	if caller == "" {
+		return func() {}, 0, nil
+	}
+
+	now := time.Now()
+	service, method := splitMethod(fullMethod)
+	key := caller + " " + name
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	state, ok := l.callers[key]
+	if !ok {
+		state = &callerLimit{}
+		if limit.Rate > 0 {
+			state.limiter = rate.NewLimiter(rate.Limit(limit.Rate), limit.burst())
+		}
+		l.callers[key] = state
+		rateLimitCallers.Set(float64(len(l.callers)))
+	}
+	state.lastCall = now
+
+	if limit.Concurrency > 0 && state.running >= limit.Concurrency {
+		rateLimitRejected.WithLabelValues(service, method, "concurrency").Inc()
+		return nil, time.Second, rateLimitError(time.Second, "limit of %d concurrent calls reached for %s", limit.Concurrency, name)
+	}
+
+	if state.limiter != nil {
+		reservation := state.limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			rateLimitRejected.WithLabelValues(service, method, "rate").Inc()
+			return nil, delay, rateLimitError(delay, "rate limit of %v calls per second reached for %s", limit.Rate, name)
+		}
+	}
+
+	if limit.Concurrency == 0 {
+		return func() {}, 0, nil
+	}
+
+	state.running++
+	rateLimitRunning.WithLabelValues(name).Inc()
+
+	return func() {
+		l.mu.Lock()
+		state.running--
+		state.lastCall = time.Now()
+		l.mu.Unlock()
+		rateLimitRunning.WithLabelValues(name).Dec()
+	}, 0, nil
+}
+
+// sweep forgets the callers idle for rateLimitIdle, at most once a minute. It must be called with the lock held
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, state := range l.callers {
+		if state.running == 0 && now.Sub(state.lastCall) > rateLimitIdle {
+			delete(l.callers, key)
+		}
+	}
+	rateLimitCallers.Set(float64(len(l.callers)))
+}
+
+// rateLimitError is the ResourceExhausted error of a rejected call, with how long to wait before retrying
+func rateLimitError(retryAfter time.Duration, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...) + fmt.Sprintf(", retry in %s", retryAfter.Round(time.Millisecond))
+	s, err := status.New(codes.ResourceExhausted, message).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+
+	return s.Err()
+}
+
+// retryAfterMetadata is the trailer with the seconds to wait, rounded up
+func retryAfterMetadata(retryAfter time.Duration) metadata.MD {
+	return metadata.Pairs(RetryAfterMetadataKey, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
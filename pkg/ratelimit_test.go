@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// callerContext returns the context of a call with the token
+func callerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer "+token))
+}
+
+func TestRateLimiterRate(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{
+		Key:     "token",
+		Default: RateLimit{Rate: 0.001, Burst: 2},
+		Methods: map[string]RateLimit{"opencp.DomainService": {}},
+	})
+	list := "/opencp.VirtualMachineService/ListVirtualMachine"
+	alice, bob := callerContext("alice"), callerContext("bob")
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := limiter.acquire(alice, list); err != nil {
+			t.Fatalf("call %d within the burst: %v", i+1, err)
+		}
+	}
+
+	_, retryAfter, err := limiter.acquire(alice, list)
+	if status.Code(err) != codes.ResourceExhausted || retryAfter <= 0 {
+		t.Fatalf("call over the burst: got %v, retry after %v, want ResourceExhausted", err, retryAfter)
+	}
+
+	if _, _, err := limiter.acquire(bob, list); err != nil {
+		t.Fatalf("another caller is limited with the first: %v", err)
+	}
+	if _, _, err := limiter.acquire(alice, "/opencp.DomainService/ListDomain"); err != nil {
+		t.Fatalf("a service without a limit is limited: %v", err)
+	}
+	if _, _, err := limiter.acquire(context.Background(), list); err != nil {
+		t.Fatalf("a call without a token is limited: %v", err)
+	}
+}
+
+func TestRateLimiterConcurrency(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{
+		Key:     "token",
+		Methods: map[string]RateLimit{"opencp.VirtualMachineService/CreateVirtualMachine": {Concurrency: 1}},
+	})
+	create := "/opencp.VirtualMachineService/CreateVirtualMachine"
+	ctx := callerContext("alice")
+
+	release, _, err := limiter.acquire(ctx, create)
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, _, err := limiter.acquire(ctx, create); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("second call while the first runs: got %v, want ResourceExhausted", err)
+	}
+	if _, _, err := limiter.acquire(ctx, "/opencp.VirtualMachineService/ListVirtualMachine"); err != nil {
+		t.Fatalf("another method of the service is limited: %v", err)
+	}
+
+	release()
+	if _, _, err := limiter.acquire(ctx, create); err != nil {
+		t.Fatalf("call after the first ended: %v", err)
+	}
+}
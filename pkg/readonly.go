@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// ReadWriteMode is the mode of the callers that can change resources
+	ReadWriteMode = "read-write"
+
+	// ReadOnlyMode is the mode of the callers that can only read resources
+	ReadOnlyMode = "read-only"
+)
+
+// ReadOnly rejects the calls changing resources for every caller when the whole server is read-only,
+// or for the callers whose token has one of the hashes
+type ReadOnly struct {
+	server bool
+	tokens map[string]bool
+}
+
+// NewReadOnly creates the ReadOnly of a server, tokenHashes are the SHA-256 hashes of the read-only tokens
+func NewReadOnly(server bool, tokenHashes []string) *ReadOnly {
+	tokens := map[string]bool{}
+	for _, hash := range tokenHashes {
+		tokens[hash] = true
+	}
+
+	return &ReadOnly{server: server, tokens: tokens}
+}
+
+// Mode returns the mode of the server, or of the token of the call when it sets one
+func (r *ReadOnly) Mode(ctx context.Context) string {
+	if r.readOnly(ctx) {
+		return ReadOnlyMode
+	}
+
+	return ReadWriteMode
+}
+
+// ServerMode returns the mode of the server, whatever the token
+func (r *ReadOnly) ServerMode() string {
+	if r.server {
+		return ReadOnlyMode
+	}
+
+	return ReadWriteMode
+}
+
+// UnaryServerInterceptor rejects the unary calls changing resources of the read-only callers
+func (r *ReadOnly) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := r.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the stream calls changing resources of the read-only callers
+func (r *ReadOnly) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := r.check(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// readOnly returns true if the caller of the call can only read resources
+func (r *ReadOnly) readOnly(ctx context.Context) bool {
+	if r.server {
+		return true
+	}
+	if len(r.tokens) == 0 {
+		return false
+	}
+
+	return r.tokens[CallerTokenHash(ctx)]
+}
+
+// check returns PermissionDenied if the method changes resources and the caller is read-only
+func (r *ReadOnly) check(ctx context.Context, fullMethod string) error {
+	_, method := splitMethod(fullMethod)
+	if !isMutatingMethod(method) || !r.readOnly(ctx) {
+		return nil
+	}
+
+	Logger(ctx).WithField("grpc.method", fullMethod).Warn("rejected a call changing resources from a read-only caller")
+
+	if r.server {
+		return status.Errorf(codes.PermissionDenied, "%s is not allowed, the server is read-only", method)
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not allowed, the token is read-only", method)
+}
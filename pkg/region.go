@@ -0,0 +1,284 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/civo/civogo"
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RegionMetadataKey is the gRPC metadata used to pick the Civo region of a call,
+	// it takes a region code, a comma separated list of codes or * for every region
+	RegionMetadataKey = "x-opencp-region"
+
+	// RegionLabel is the label set on the items returned by the List calls with the region they are in
+	RegionLabel = "opencp.io/region"
+
+	// allRegions is the region metadata value used to list the resources of every region
+	allRegions = "*"
+)
+
+// globalServices are the services whose resources are not regional in Civo, their calls are never
+// made per region
+var globalServices = map[string]bool{
+	opencpspec.DomainService_ServiceDesc.ServiceName: true,
+	opencpspec.SSHKeyService_ServiceDesc.ServiceName: true,
+}
+
+// regionCache keeps the regions available to each token, so they are not fetched on every call
+type regionCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]regionCacheEntry
+}
+
+// regionCacheEntry is the list of regions of a token
+type regionCacheEntry struct {
+	regions []civogo.Region
+	expires time.Time
+}
+
+func newRegionCache(ttl time.Duration) *regionCache {
+	return &regionCache{
+		ttl:     ttl,
+		entries: map[string]regionCacheEntry{},
+	}
+}
+
+// regions returns the regions available to the token, asking the Civo API when they are not cached
+func (c *regionCache) regions(token string, client Provider) ([]civogo.Region, error) {
+	key := TokenHash(token)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.regions, nil
+	}
+
+	regions, err := client.ListRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = regionCacheEntry{regions: regions, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+
+	return regions, nil
+}
+
+// providers returns a provider for each of the requested regions, after checking the token can use them
+func (c *regionCache) providers(token string, client Provider, requested []string, newProvider ProviderFactory) ([]Provider, error) {
+	available, err := c.regions(token, client)
+	if err != nil {
+		return nil, err
+	}
+
+	codesAvailable := []string{}
+	for _, region := range available {
+		codesAvailable = append(codesAvailable, region.Code)
+	}
+
+	// Every region of the account
+	if len(requested) == 1 && requested[0] == allRegions {
+		requested = codesAvailable
+	}
+
+	providers := []Provider{}
+	seen := map[string]bool{}
+	for _, name := range requested {
+		var code string
+		for _, availableCode := range codesAvailable {
+			if strings.EqualFold(availableCode, name) {
+				code = availableCode
+			}
+		}
+
+		if code == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown region %q, the available regions are %s", name, strings.Join(codesAvailable, ", "))
+		}
+
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		provider, err := newProvider(token, code)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no region is available to the account")
+	}
+
+	return providers, nil
+}
+
+// requestedRegions returns the regions set in the metadata of the call
+func requestedRegions(ctx context.Context) []string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	regions := []string{}
+	for _, value := range md.Get(RegionMetadataKey) {
+		regions = append(regions, splitList(value)...)
+	}
+
+	// * can't be mixed with other regions
+	for _, region := range regions {
+		if region == allRegions && len(regions) > 1 {
+			return []string{allRegions}
+		}
+	}
+
+	return regions
+}
+
+// RegionUnaryServerInterceptor runs the List calls made for several regions once per region,
+// merging the items of every region. The items of every List call get the RegionLabel
+func RegionUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		service, method := splitMethod(info.FullMethod)
+		if globalServices[service] {
+			return handler(ctx, req)
+		}
+
+		isList := strings.HasPrefix(method, "List")
+
+		clients, _ := ctx.Value("regions").([]Provider)
+		if len(clients) > 1 && !isList {
+			return nil, status.Errorf(codes.InvalidArgument, "only the List calls can use more than one region, %s got %d", info.FullMethod, len(clients))
+		}
+
+		if len(clients) <= 1 {
+			resp, err := handler(ctx, req)
+			if err != nil || !isList {
+				return resp, err
+			}
+
+			if client, ok := ctx.Value("client").(Provider); ok {
+				labelRegion(resp, client.GetRegion())
+			}
+
+			return resp, nil
+		}
+
+		// Call the handler for every region at the same time
+		responses := make([]interface{}, len(clients))
+		errs := make([]error, len(clients))
+		var wg sync.WaitGroup
+		for i, client := range clients {
+			wg.Add(1)
+			go func(i int, client Provider) {
+				defer wg.Done()
+				regionCtx := context.WithValue(ctx, "client", client)
+				responses[i], errs[i] = handler(regionCtx, req)
+			}(i, client)
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				return nil, regionError(clients[i].GetRegion(), err)
+			}
+		}
+
+		// Label the items of every region and add them to the first response
+		for i, resp := range responses {
+			labelRegion(resp, clients[i].GetRegion())
+			if i > 0 {
+				mergeItems(responses[0], resp)
+			}
+		}
+
+		return responses[0], nil
+	}
+}
+
+// regionError adds the region to the message of an error, keeping its code and details
+func regionError(region string, err error) error {
+	s := status.Convert(CivoErrorToStatus(err)).Proto()
+	s.Message = "region " + region + ": " + s.Message
+	return status.FromProto(s).Err()
+}
+
+// listItems returns the Items of a List response, they all have the same shape
+func listItems(resp interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(resp)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	items := v.Elem().FieldByName("Items")
+	if !items.IsValid() || items.Kind() != reflect.Slice {
+		return reflect.Value{}, false
+	}
+
+	return items, true
+}
+
+// mergeItems appends the Items of from to the Items of to
+func mergeItems(to, from interface{}) {
+	toItems, ok := listItems(to)
+	if !ok {
+		return
+	}
+
+	fromItems, ok := listItems(from)
+	if !ok {
+		return
+	}
+
+	toItems.Set(reflect.AppendSlice(toItems, fromItems))
+}
+
+// labelRegion sets the RegionLabel on the metadata of every item of a List response
+func labelRegion(resp interface{}, region string) {
+	if region == "" {
+		return
+	}
+
+	items, ok := listItems(resp)
+	if !ok {
+		return
+	}
+
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i)
+		if item.Kind() != reflect.Ptr || item.IsNil() {
+			continue
+		}
+
+		field := item.Elem().FieldByName("Metadata")
+		if !field.IsValid() {
+			continue
+		}
+
+		meta, ok := field.Interface().(*metav1.ObjectMeta)
+		if !ok || meta == nil {
+			continue
+		}
+
+		if meta.Labels == nil {
+			meta.Labels = map[string]string{}
+		}
+		meta.Labels[RegionLabel] = region
+	}
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// regionCall authenticates a call with the region metadata, as the auth middleware of the server does
+func regionCall(regions string) (context.Context, error) {
+	md := metadata.Pairs("authorization", "bearer region-token", RegionMetadataKey, regions)
+	return AuthMiddleware(NewMemoryBackend().Provider, "LON1")(metadata.NewIncomingContext(context.Background(), md))
+}
+
+func TestRegionUnaryServerInterceptor(t *testing.T) {
+	s := &Server{}
+	interceptor := RegionUnaryServerInterceptor()
+	service := opencpspec.NamespaceService_ServiceDesc.ServiceName
+	list := &grpc.UnaryServerInfo{FullMethod: "/" + service + "/ListNamespace"}
+	listNamespaces := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.ListNamespace(ctx, req.(*opencpspec.FilterOptions))
+	}
+
+	tests := []struct {
+		regions string
+		want    []string
+	}{
+		{"nyc1", []string{"NYC1"}},
+		{"lon1,NYC1,lon1", []string{"LON1", "NYC1"}},
+		{"*", []string{"FRA1", "LON1", "NYC1", "PHX1"}},
+	}
+	for _, test := range tests {
+		ctx, err := regionCall(test.regions)
+		if err != nil {
+			t.Fatalf("authenticating with the regions %s: %v", test.regions, err)
+		}
+
+		resp, err := interceptor(ctx, &opencpspec.FilterOptions{}, list, listNamespaces)
+		if err != nil {
+			t.Fatalf("listing the namespaces of %s: %v", test.regions, err)
+		}
+
+		// Every region has its default namespace
+		got := []string{}
+		for _, namespace := range resp.(*opencpspec.NamespaceList).Items {
+			got = append(got, namespace.Metadata.Labels[RegionLabel])
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(test.want, ",") {
+			t.Fatalf("listing the namespaces of %s got the regions %v, want %v", test.regions, got, test.want)
+		}
+	}
+
+	// Only the List calls run in several regions
+	ctx, err := regionCall("LON1,NYC1")
+	if err != nil {
+		t.Fatalf("authenticating with two regions: %v", err)
+	}
+	get := &grpc.UnaryServerInfo{FullMethod: "/" + service + "/GetNamespace"}
+	if _, err := interceptor(ctx, &opencpspec.FilterOptions{}, get, listNamespaces); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("getting a namespace in two regions: got %v, want InvalidArgument", err)
+	}
+
+	if _, err := regionCall("MARS1"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("authenticating with an unknown region: got %v, want InvalidArgument", err)
+	}
+}
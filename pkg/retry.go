@@ -0,0 +1,178 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var civoAPIRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "opencp_civo_api_retries_total",
+	Help: "Number of Civo API calls retried, by endpoint and the code of the failed attempt.",
+}, []string{"endpoint", "code"})
+
+// retryAfterRegexp finds a Retry-After in seconds in the error of a Civo API call
+var retryAfterRegexp = regexp.MustCompile(`(?i)retry[-_ ]after"?\s*[:=]?\s*"?(\d+)`)
+
+// RetryConfig is how the failed Civo API calls are retried
+type RetryConfig struct {
+	// MaxAttempts is the most times a call is tried, 1 turns the retries off
+	MaxAttempts int `yaml:"maxAttempts"`
+
+	// InitialBackoff is the wait before the first retry, doubled for each retry up to MaxBackoff
+	InitialBackoff Duration `yaml:"initialBackoff"`
+	MaxBackoff     Duration `yaml:"maxBackoff"`
+}
+
+// validate returns the problems of the retry settings
+func (c *RetryConfig) validate() []string {
+	problems := []string{}
+
+	if c.MaxAttempts < 1 {
+		problems = append(problems, fmt.Sprintf("the retry max attempts %d must be at least 1", c.MaxAttempts))
+	}
+
+	if c.InitialBackoff.Duration <= 0 || c.MaxBackoff.Duration < c.InitialBackoff.Duration {
+		problems = append(problems, "the retry initial backoff must be positive and the max backoff at least as long")
+	}
+
+	return problems
+}
+
+// CivoAPIRetries is the ProviderCallHook retrying the Civo API calls that failed with a transient error,
+// waiting an exponential backoff with jitter, or a longer Retry-After found in the error, up to MaxBackoff.
+// The reads, the deletes and the calls setting a value are retried on rate limits, server errors and network errors. The other calls
+// are only retried when the Civo API can't have run them: on a rate limit or when the connection failed
+func CivoAPIRetries(config RetryConfig) ProviderCallHook {
+	return func(ctx context.Context, endpoint string, next func() error) error {
+		idempotent := isIdempotentEndpoint(endpoint)
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			err = next()
+			if err == nil {
+				return nil
+			}
+
+			// The answer of a deleting attempt was lost, and the resource is gone
+			if attempt > 1 && strings.HasPrefix(endpoint, "Delete") && status.Code(CivoErrorToStatus(err)) == codes.NotFound {
+				return nil
+			}
+
+			if attempt >= config.MaxAttempts || !isRetryable(err, idempotent) {
+				return err
+			}
+
+			delay := retryBackoff(config, attempt)
+			if retryAfter := civoRetryAfter(err); retryAfter > delay {
+				delay = retryAfter
+				if delay > config.MaxBackoff.Duration {
+					delay = config.MaxBackoff.Duration
+				}
+			}
+
+			// A retry that can't start before the call times out would only fail later
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				return err
+			}
+
+			code := status.Code(CivoErrorToStatus(err)).String()
+			civoAPIRetries.WithLabelValues(endpoint, code).Inc()
+			Logger(ctx).WithFields(logrus.Fields{
+				"civo.endpoint": endpoint,
+				"civo.attempt":  attempt,
+				"civo.code":     code,
+				"civo.retry_in": delay.String(),
+			}).WithError(err).Warn("retrying the Civo API call")
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// isIdempotentEndpoint returns true if calling the endpoint twice does the same as calling it once
+func isIdempotentEndpoint(endpoint string) bool {
+	for _, prefix := range []string{"List", "Find", "Get", "Delete", "Set"} {
+		if strings.HasPrefix(endpoint, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRetryable returns true if a call that failed with err can be tried again
+func isRetryable(err error, idempotent bool) bool {
+	httpCode := civoHTTPStatus(err)
+
+	// A rate limited call was not run, and a call that could not connect was not sent
+	if httpCode == 429 {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	if !idempotent {
+		return false
+	}
+
+	switch httpCode {
+	case 500, 502, 503, 504:
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryBackoff returns the wait before the retry following attempt, a random time up to the
+// exponential backoff so the callers retrying together spread out
+func retryBackoff(config RetryConfig, attempt int) time.Duration {
+	backoff := config.InitialBackoff.Duration << (attempt - 1)
+	if backoff > config.MaxBackoff.Duration || backoff <= 0 {
+		backoff = config.MaxBackoff.Duration
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// civoRetryAfter returns a wait found in the error, or 0. civogo drops the headers of the answers, so the
+// Retry-After header of the Civo API is never seen: only a retry after in the body of the answer or the
+// RetryInfo of a status is found
+func civoRetryAfter(err error) time.Duration {
+	if s, ok := status.FromError(err); ok {
+		for _, detail := range s.Details() {
+			if info, ok := detail.(*errdetails.RetryInfo); ok {
+				return info.RetryDelay.AsDuration()
+			}
+		}
+	}
+
+	if match := retryAfterRegexp.FindStringSubmatch(err.Error()); match != nil {
+		seconds, _ := strconv.Atoi(match[1])
+		return time.Duration(seconds) * time.Second
+	}
+
+	return 0
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/civo/civogo"
+)
+
+func TestIsRetryable(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	read := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}
+
+	tests := []struct {
+		name       string
+		err        error
+		idempotent bool
+		want       bool
+	}{
+		{"rate limited create", civogo.HTTPError{Code: 429}, false, true},
+		{"create that could not connect", dial, false, true},
+		{"create failing with a server error", civogo.HTTPError{Code: 502}, false, false},
+		{"create cut while reading the answer", read, false, false},
+		{"read failing with a server error", civogo.HTTPError{Code: 503}, true, true},
+		{"read cut while reading the answer", read, true, true},
+		{"read of a missing resource", civogo.HTTPError{Code: 404}, true, false},
+		{"invalid read", errors.New("DatabaseInstanceNotFoundError: not found"), true, false},
+	}
+	for _, test := range tests {
+		if got := isRetryable(test.err, test.idempotent); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 5, InitialBackoff: Duration{100 * time.Millisecond}, MaxBackoff: Duration{time.Second}}
+
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 70: time.Second} {
+		for i := 0; i < 20; i++ {
+			if backoff := retryBackoff(config, attempt); backoff < max/2 || backoff > max {
+				t.Fatalf("attempt %d waits %v, want between %v and %v", attempt, backoff, max/2, max)
+			}
+		}
+	}
+}
+
+func TestCivoAPIRetries(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 3, InitialBackoff: Duration{time.Millisecond}, MaxBackoff: Duration{10 * time.Millisecond}}
+	retries := CivoAPIRetries(config)
+
+	tests := []struct {
+		name     string
+		endpoint string
+		errs     []error
+		attempts int
+		fails    bool
+	}{
+		{"read succeeding on the second try", "ListAllInstances", []error{civogo.HTTPError{Code: 502}, nil}, 2, false},
+		{"read failing every time", "ListAllInstances", []error{civogo.HTTPError{Code: 503}, civogo.HTTPError{Code: 503}, civogo.HTTPError{Code: 503}}, 3, true},
+		{"create failing with a server error", "CreateInstance", []error{civogo.HTTPError{Code: 500}}, 1, true},
+		{"delete whose first answer was lost", "DeleteInstance", []error{civogo.HTTPError{Code: 504}, civogo.HTTPError{Code: 404}}, 2, false},
+		{"retry after longer than the max backoff", "ListAllInstances", []error{errors.New(`UnknownError: Error: code: 429, message: {"retry_after":"60"}`), nil}, 2, false},
+	}
+	for _, test := range tests {
+		attempts := 0
+		start := time.Now()
+		err := retries(context.Background(), test.endpoint, func() error {
+			attempts++
+			return test.errs[attempts-1]
+		})
+		if attempts != test.attempts || (err != nil) != test.fails {
+			t.Errorf("%s: %d attempts and the error %v, want %d attempts and failing %v", test.name, attempts, err, test.attempts, test.fails)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: took %v, the waits must stay under the max backoff", test.name, elapsed)
+		}
+	}
+
+	// A call that would time out before the next try is not retried
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	attempts := 0
+	slow := CivoAPIRetries(RetryConfig{MaxAttempts: 3, InitialBackoff: Duration{time.Minute}, MaxBackoff: Duration{time.Minute}})
+	slow(ctx, "ListAllInstances", func() error {
+		attempts++
+		return civogo.HTTPError{Code: 503}
+	})
+	if attempts != 1 {
+		t.Errorf("a call timing out before the backoff was tried %d times, want once", attempts)
+	}
+}
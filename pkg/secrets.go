@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"context"
+	"strings"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RevealSecretsMetadataKey is the metadata key asking for the secrets of the answer in clear, e.g. true
+const RevealSecretsMetadataKey = "x-opencp-reveal-secrets"
+
+// maskedSecret replaces the secrets in the answers
+const maskedSecret = "********"
+
+// RevealPolicy returns nil if the caller of the method may see the secrets of the request in clear,
+// or the error the call fails with
+type RevealPolicy func(ctx context.Context, fullMethod string, req interface{}) error
+
+// RevealAudit records the secrets shown in clear to the caller of the method
+type RevealAudit func(ctx context.Context, fullMethod string, secrets []string)
+
+// DenyReveal is the RevealPolicy keeping the secrets masked for every caller
+func DenyReveal(ctx context.Context, fullMethod string, req interface{}) error {
+	return status.Error(codes.PermissionDenied, "revealing the secrets is turned off on this server")
+}
+
+// SecretsUnaryServerInterceptor masks the database passwords and the object storage secret keys in
+// the answers. They are kept when the caller sets RevealSecretsMetadataKey and the policy allows it,
+// each reveal is then recorded with audit. The answers of the creates are never masked, they are the
+// only way for the caller to learn a generated password or secret key
+func SecretsUnaryServerInterceptor(policy RevealPolicy, audit RevealAudit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, method := splitMethod(info.FullMethod); strings.HasPrefix(method, "Create") {
+			return handler(ctx, req)
+		}
+
+		reveal := revealRequested(ctx)
+		if reveal {
+			if err := policy(ctx, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		if !reveal {
+			visitSecrets(resp, func(name string, value *string) {
+				*value = maskedSecret
+			})
+			return resp, nil
+		}
+
+		revealed := []string{}
+		visitSecrets(resp, func(name string, value *string) {
+			revealed = append(revealed, name)
+		})
+		if len(revealed) > 0 {
+			audit(ctx, info.FullMethod, revealed)
+		}
+
+		return resp, nil
+	}
+}
+
+// revealRequested returns true if the caller asked for the secrets in clear
+func revealRequested(ctx context.Context) bool {
+	return metadataFlag(ctx, RevealSecretsMetadataKey)
+}
+
+// visitSecrets calls visit with the name and the address of every secret that is set in the answer
+func visitSecrets(resp interface{}, visit func(name string, value *string)) {
+	switch r := resp.(type) {
+	case *opencpspec.Database:
+		databaseSecrets(r, visit)
+	case *opencpspec.DatabaseList:
+		for _, db := range r.Items {
+			databaseSecrets(db, visit)
+		}
+	case *opencpspec.ObjectStorageCredential:
+		objectStorageCredentialSecrets(r, visit)
+	case *opencpspec.ObjectStorageCredentialList:
+		for _, credential := range r.Items {
+			objectStorageCredentialSecrets(credential, visit)
+		}
+	}
+}
+
+// databaseSecrets visits the password of a database
+func databaseSecrets(db *opencpspec.Database, visit func(name string, value *string)) {
+	if db == nil || db.Status == nil || db.Status.Password == "" {
+		return
+	}
+
+	visit(secretName("database", db.Metadata, "password"), &db.Status.Password)
+}
+
+// objectStorageCredentialSecrets visits the secret key of an object storage credential
+func objectStorageCredentialSecrets(credential *opencpspec.ObjectStorageCredential, visit func(name string, value *string)) {
+	if credential == nil || credential.Spec == nil || credential.Spec.SecretKey == "" {
+		return
+	}
+
+	visit(secretName("objectstoragecredential", credential.Metadata, "secretKey"), &credential.Spec.SecretKey)
+}
+
+// secretName names a secret after its resource, such as database/dev/db1.password
+func secretName(kind string, meta *metav1.ObjectMeta, field string) string {
+	if meta == nil {
+		return kind + "." + field
+	}
+
+	name := kind + "/"
+	if meta.Namespace != "" {
+		name += meta.Namespace + "/"
+	}
+
+	return name + meta.Name + "." + field
+}
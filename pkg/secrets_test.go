@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// databaseHandler answers with a database and its password, as the database calls do
+func databaseHandler(ctx context.Context, req interface{}) (interface{}, error) {
+	return &opencpspec.DatabaseList{Items: []*opencpspec.Database{{
+		Metadata: &metav1.ObjectMeta{Name: "db1", Namespace: "dev"},
+		Status:   &opencpspec.DatabaseStatus{Password: "hunter2"},
+	}}}, nil
+}
+
+func TestSecretsUnaryServerInterceptor(t *testing.T) {
+	allow := func(ctx context.Context, fullMethod string, req interface{}) error { return nil }
+	revealed := []string{}
+	audit := func(ctx context.Context, fullMethod string, secrets []string) {
+		revealed = append(revealed, secrets...)
+	}
+	list := &grpc.UnaryServerInfo{FullMethod: "/opencp.DatabaseService/ListDatabase"}
+	create := &grpc.UnaryServerInfo{FullMethod: "/opencp.DatabaseService/CreateDatabase"}
+	reveal := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RevealSecretsMetadataKey, "true"))
+
+	tests := []struct {
+		name     string
+		policy   RevealPolicy
+		ctx      context.Context
+		info     *grpc.UnaryServerInfo
+		password string
+		revealed int
+	}{
+		{"list", allow, context.Background(), list, maskedSecret, 0},
+		{"create", DenyReveal, context.Background(), create, "hunter2", 0},
+		{"list revealing the secrets", allow, reveal, list, "hunter2", 1},
+	}
+	for _, test := range tests {
+		revealed = revealed[:0]
+		resp, err := SecretsUnaryServerInterceptor(test.policy, audit)(test.ctx, nil, test.info, databaseHandler)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if password := resp.(*opencpspec.DatabaseList).Items[0].Status.Password; password != test.password {
+			t.Errorf("%s: got the password %q, want %q", test.name, password, test.password)
+		}
+		if len(revealed) != test.revealed {
+			t.Errorf("%s: audited the reveals %v, want %d", test.name, revealed, test.revealed)
+		}
+	}
+	if len(revealed) == 1 && revealed[0] != "database/dev/db1.password" {
+		t.Errorf("audited the reveal of %s, want database/dev/db1.password", revealed[0])
+	}
+
+	if _, err := SecretsUnaryServerInterceptor(DenyReveal, audit)(reveal, nil, list, databaseHandler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("revealing the secrets against the policy: got %v, want PermissionDenied", err)
+	}
+}
@@ -1,10 +1,18 @@
 package pkg
 
 import (
+	"fmt"
+	"sort"
+
+	civov1 "github.com/civo/civo-opencp/api/civo/v1"
 	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
+	civov1.UnimplementedPowerServiceServer
+	civov1.UnimplementedDiskImageServiceServer
+
 	opencpspec.LoginServer
 	opencpspec.VirtualMachineServiceServer
 	opencpspec.KubernetesClusterServiceServer
@@ -17,3 +25,83 @@ type Server struct {
 	opencpspec.ObjectStorageServiceServer
 	opencpspec.ObjectStorageCredentialServiceServer
 }
+
+// service is an OpenCP service the server can serve
+type service struct {
+	desc     *grpc.ServiceDesc
+	register func(grpc.ServiceRegistrar, *Server)
+}
+
+// services are the OpenCP services by the name used in the config
+var services = map[string]service{
+	"login": {&opencpspec.Login_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterLoginServer(r, s)
+	}},
+	"virtualmachine": {&opencpspec.VirtualMachineService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterVirtualMachineServiceServer(r, s)
+		civov1.RegisterPowerServiceServer(r, s)
+		civov1.RegisterDiskImageServiceServer(r, s)
+	}},
+	"kubernetescluster": {&opencpspec.KubernetesClusterService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterKubernetesClusterServiceServer(r, s)
+	}},
+	"namespace": {&opencpspec.NamespaceService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterNamespaceServiceServer(r, s)
+	}},
+	"domain": {&opencpspec.DomainService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterDomainServiceServer(r, s)
+	}},
+	"sshkey": {&opencpspec.SSHKeyService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterSSHKeyServiceServer(r, s)
+	}},
+	"firewall": {&opencpspec.FirewallService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterFirewallServiceServer(r, s)
+	}},
+	"ip": {&opencpspec.IpService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterIpServiceServer(r, s)
+	}},
+	"database": {&opencpspec.DatabaseService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterDatabaseServiceServer(r, s)
+	}},
+	"objectstorage": {&opencpspec.ObjectStorageService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterObjectStorageServiceServer(r, s)
+	}},
+	"objectstoragecredential": {&opencpspec.ObjectStorageCredentialService_ServiceDesc, func(r grpc.ServiceRegistrar, s *Server) {
+		opencpspec.RegisterObjectStorageCredentialServiceServer(r, s)
+	}},
+}
+
+// civoExtensions are the Civo services registered along with an OpenCP service
+var civoExtensions = map[string][]*grpc.ServiceDesc{
+	"virtualmachine": {&civov1.PowerService_ServiceDesc, &civov1.DiskImageService_ServiceDesc},
+}
+
+// ServiceNames returns the names of all the services that can be enabled in the config
+func ServiceNames() []string {
+	names := []string{}
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// RegisterServices registers the services with the given names and returns their gRPC service names
+func RegisterServices(r grpc.ServiceRegistrar, s *Server, names []string) ([]string, error) {
+	registered := []string{}
+	for _, name := range names {
+		svc, ok := services[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown service %q", name)
+		}
+
+		svc.register(r, s)
+		registered = append(registered, svc.desc.ServiceName)
+		for _, extension := range civoExtensions[name] {
+			registered = append(registered, extension.ServiceName)
+		}
+	}
+
+	return registered, nil
+}
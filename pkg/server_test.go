@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestConn serves every service over the memory backend, with the authentication and the
+// error handling of the server, and returns a connection to it
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	clientCache := NewClientCache(NewMemoryBackend().Provider, 16, time.Minute)
+	server := grpc.NewServer(grpc_middleware.WithUnaryServerChain(
+		grpc_auth.UnaryServerInterceptor(AuthMiddleware(clientCache.Provider, "")),
+		ProviderUnaryServerInterceptor(),
+		ErrorUnaryServerInterceptor(),
+	))
+	if _, err := RegisterServices(server, &Server{}, ServiceNames()); err != nil {
+		t.Fatalf("registering the services: %v", err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dialing the server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// withToken returns a context calling with the token
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "bearer "+token)
+}
+
+// createTestVirtualMachine creates a virtual machine with the name in the default namespace of the caller
+func createTestVirtualMachine(t *testing.T, ctx context.Context, virtualMachines opencpspec.VirtualMachineServiceClient, name string) *opencpspec.VirtualMachine {
+	t.Helper()
+
+	vm, err := virtualMachines.CreateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:     &opencpspec.VirtualMachineSpec{Size: "g3.small", Image: "ubuntu-jammy", Ipv4: true},
+	})
+	if err != nil {
+		t.Fatalf("creating the virtual machine %s: %v", name, err)
+	}
+
+	return vm
+}
+
+func TestServerMemorySmoke(t *testing.T) {
+	conn := newTestConn(t)
+	ctx := withToken("smoke-test-token")
+
+	namespaces := opencpspec.NewNamespaceServiceClient(conn)
+	virtualMachines := opencpspec.NewVirtualMachineServiceClient(conn)
+
+	if _, err := namespaces.ListNamespace(context.Background(), &opencpspec.FilterOptions{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("calling without a token: got %v, want Unauthenticated", err)
+	}
+
+	if _, err := namespaces.CreateNamespace(ctx, &opencpspec.Namespace{Metadata: &metav1.ObjectMeta{Name: "smoke"}}); err != nil {
+		t.Fatalf("creating the namespace: %v", err)
+	}
+	all, err := namespaces.ListNamespace(ctx, &opencpspec.FilterOptions{})
+	if err != nil || len(all.Items) != 2 {
+		t.Fatalf("listing the namespaces: %v, %v, want default and smoke", all, err)
+	}
+	namespace := "default"
+
+	vm := createTestVirtualMachine(t, ctx, virtualMachines, "vm1")
+	if vm.Spec.Image != "ubuntu-jammy" || vm.Metadata.Namespace != namespace {
+		t.Fatalf("created virtual machine has image %q in namespace %q, want ubuntu-jammy in default", vm.Spec.Image, vm.Metadata.Namespace)
+	}
+
+	list, err := virtualMachines.ListVirtualMachine(ctx, &opencpspec.FilterOptions{Namespace: &namespace})
+	if err != nil || len(list.Items) != 1 {
+		t.Fatalf("listing the virtual machines: %v, %v", list, err)
+	}
+
+	id := string(vm.Metadata.UID)
+	if _, err := virtualMachines.DeleteVirtualMachine(ctx, &opencpspec.FilterOptions{Id: &id}); err != nil {
+		t.Fatalf("deleting the virtual machine: %v", err)
+	}
+	if _, err := virtualMachines.GetVirtualMachine(ctx, &opencpspec.FilterOptions{Id: &id}); status.Code(err) != codes.NotFound {
+		t.Fatalf("getting the deleted virtual machine: got %v, want NotFound", err)
+	}
+
+	other := withToken("another-token")
+	if list, err := namespaces.ListNamespace(other, &opencpspec.FilterOptions{}); err != nil || len(list.Items) != 1 {
+		t.Fatalf("another account sees the namespaces %v, %v, want only its default one", list, err)
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 func (s *Server) ListSSHKey(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.SSHKeyList, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	sshKeys, err := client.ListSSHKeys()
 	if err != nil {
@@ -40,7 +40,7 @@ func (s *Server) ListSSHKey(ctx context.Context, option *opencpspec.FilterOption
 }
 
 func (s *Server) GetSSHKey(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.SSHKey, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	sshKey, err := client.FindSSHKey(*option.Name)
 	if err != nil {
@@ -65,7 +65,7 @@ func (s *Server) GetSSHKey(ctx context.Context, option *opencpspec.FilterOptions
 
 func (s *Server) CreateSSHKey(ctx context.Context, in *opencpspec.SSHKey) (*opencpspec.SSHKey, error) {
 	// Civo client from the ctx
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Create the SSH key
 	_, err := client.NewSSHKey(in.Metadata.Name, in.Spec.PublicKey)
@@ -83,13 +83,17 @@ func (s *Server) CreateSSHKey(ctx context.Context, in *opencpspec.SSHKey) (*open
 }
 
 func (s *Server) DeleteSSHKey(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.SSHKey, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	sshKey, err := s.GetSSHKey(ctx, option)
 	if err != nil {
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return sshKey, nil
+	}
+
 	if sshKey != nil {
 		_, err = client.DeleteSSHKey(string(sshKey.Metadata.UID))
 		if err != nil {
@@ -99,3 +103,44 @@ func (s *Server) DeleteSSHKey(ctx context.Context, option *opencpspec.FilterOpti
 
 	return sshKey, nil
 }
+
+// resolveSSHKey returns the SSH key with exactly the ID or the name, NotFound if there is none and
+// InvalidArgument if several keys match. Civo's own search also matches a part of the name or the ID,
+// which could pick the wrong key for a virtual machine
+func resolveSSHKey(client Provider, nameOrID string) (*civogo.SSHKey, error) {
+	sshKeys, err := client.ListSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []civogo.SSHKey{}
+	for _, sshKey := range sshKeys {
+		if sshKey.ID == nameOrID || sshKey.Name == nameOrID {
+			matches = append(matches, sshKey)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, notFoundError("SSH key", nameOrID)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, invalidArgument("%d SSH keys match %q, use the ID of the key", len(matches), nameOrID)
+	}
+}
+
+// sshKeyNames returns the names of the SSH keys of the account by their ID
+func sshKeyNames(client Provider) (map[string]string, error) {
+	sshKeys, err := client.ListSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, sshKey := range sshKeys {
+		names[sshKey.ID] = sshKey.Name
+	}
+
+	return names, nil
+}
@@ -0,0 +1,31 @@
+package pkg
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResolveSSHKey(t *testing.T) {
+	client, err := NewMemoryBackend().Provider("ssh-token", "LON1")
+	if err != nil {
+		t.Fatalf("creating the memory provider: %v", err)
+	}
+	for _, name := range []string{"web", "web-2"} {
+		if _, err := client.NewSSHKey(name, "ssh-ed25519 AAAA "+name); err != nil {
+			t.Fatalf("creating the SSH key %s: %v", name, err)
+		}
+	}
+
+	sshKey, err := resolveSSHKey(client, "web")
+	if err != nil || sshKey.Name != "web" {
+		t.Fatalf("resolving web: got %v, %v", sshKey, err)
+	}
+	if byID, err := resolveSSHKey(client, sshKey.ID); err != nil || byID.Name != "web" {
+		t.Fatalf("resolving the ID of web: got %v, %v", byID, err)
+	}
+	if _, err := resolveSSHKey(client, "we"); status.Code(err) != codes.NotFound {
+		t.Fatalf("resolving a part of a name: got %v, want NotFound", err)
+	}
+}
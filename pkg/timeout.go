@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TimeoutUnaryServerInterceptor fails the reads that take longer than timeout with DeadlineExceeded.
+// civogo does not take a context, so the Civo API call of a handler that timed out still runs to the end.
+// The calls changing resources are not timed out, answering DeadlineExceeded for a change that is still
+// made would mislead the caller and the audit log, so they run until their handler returns
+func TimeoutUnaryServerInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_, method := splitMethod(info.FullMethod)
+		if timeout <= 0 || isMutatingMethod(method) {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		type result struct {
+			resp interface{}
+			err  error
+		}
+
+		done := make(chan result, 1)
+		go func() {
+			resp, err := handler(ctx, req)
+			done <- result{resp, err}
+		}()
+
+		select {
+		case r := <-done:
+			return r.resp, r.err
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, status.Errorf(codes.DeadlineExceeded, "%s did not finish in %s", info.FullMethod, timeout)
+			}
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package pkg
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// tlsReloadInterval is how often the certificate files are checked for changes
+const tlsReloadInterval = 10 * time.Second
+
+// TLSConfig is the TLS configuration of the server, TLS is off when CertFile is empty
+type TLSConfig struct {
+	// CertFile and KeyFile are the PEM certificate and key of the server
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+
+	// ClientCAFile is the PEM bundle of the CAs the client certificates must be signed by, setting it turns on mTLS
+	ClientCAFile string `yaml:"clientCAFile"`
+
+	// ClientAuth is require to refuse the clients without a certificate, or optional to only verify the ones that have one
+	ClientAuth string `yaml:"clientAuth"`
+}
+
+// Enabled returns true if the server serves TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// validate returns the problems of the TLS configuration
+func (c TLSConfig) validate() []string {
+	problems := []string{}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		problems = append(problems, "the TLS certificate and key must be set together")
+	}
+
+	if c.ClientCAFile != "" && !c.Enabled() {
+		problems = append(problems, "the TLS client CA needs a TLS certificate and key")
+	}
+
+	if c.ClientAuth != "require" && c.ClientAuth != "optional" {
+		problems = append(problems, fmt.Sprintf("TLS client auth %q must be require or optional", c.ClientAuth))
+	}
+
+	return problems
+}
+
+// TLSReloader serves the certificate and client CAs of the TLS configuration,
+// loading them again when the files change so they can be rotated without a restart
+type TLSReloader struct {
+	config TLSConfig
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	checked   time.Time
+}
+
+// NewTLSReloader loads the files of the TLS configuration, failing if they are not valid
+func NewTLSReloader(config TLSConfig) (*TLSReloader, error) {
+	r := &TLSReloader{config: config}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Credentials returns the gRPC transport credentials of the server
+func (r *TLSReloader) Credentials() credentials.TransportCredentials {
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.reload()
+			return r.tlsConfig(), nil
+		},
+	})
+}
+
+// tlsConfig returns the configuration for a new connection. It is the one used for the handshake,
+// so it must offer h2 itself, the one credentials.NewTLS adds to the outer config is not used
+func (r *TLSReloader) tlsConfig() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		NextProtos:   []string{"h2"},
+	}
+
+	if r.clientCAs != nil {
+		config.ClientCAs = r.clientCAs
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		if r.config.ClientAuth == "optional" {
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return config
+}
+
+// reload loads the files again if they changed, keeping the current ones when the new files are not valid
+func (r *TLSReloader) reload() {
+	r.mu.RLock()
+	due := time.Since(r.checked) >= tlsReloadInterval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	changed := false
+	for file, modTime := range r.currentModTimes() {
+		if !modTime.Equal(r.modTime(file)) {
+			changed = true
+		}
+	}
+
+	r.mu.Lock()
+	r.checked = time.Now()
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		logrus.WithError(err).Error("failed to reload the TLS certificates, still using the previous ones")
+		return
+	}
+
+	logrus.Info("reloaded the TLS certificates")
+}
+
+// load reads the certificate, key and client CAs
+func (r *TLSReloader) load() error {
+	modTimes := r.currentModTimes()
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading the TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading the TLS client CA: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate found in the TLS client CA %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.checked = time.Now()
+
+	return nil
+}
+
+// currentModTimes returns the modification time of the files
+func (r *TLSReloader) currentModTimes() map[string]time.Time {
+	modTimes := map[string]time.Time{}
+	for _, file := range []string{r.config.CertFile, r.config.KeyFile, r.config.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+
+	return modTimes
+}
+
+// modTime returns the modification time of a file when it was loaded
+func (r *TLSReloader) modTime(file string) time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.modTimes[file]
+}
+
+// ClientSubject returns the subject of the verified client certificate of the call,
+// or an empty string when the call has no client certificate
+func ClientSubject(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return ""
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+
+	return info.State.VerifiedChains[0][0].Subject.String()
+}
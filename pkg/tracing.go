@@ -0,0 +1,226 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracerName is the name of the tracer of the server spans and of the Civo API spans
+const tracerName = "github.com/civo/civo-opencp"
+
+// TracingConfig is how the spans of the calls are exported
+type TracingConfig struct {
+	// Exporter is none, otlp, stdout or file
+	Exporter string `yaml:"exporter"`
+
+	// OTLPEndpoint is the host:port of the OTLP gRPC collector, OTLPInsecure turns off TLS to it.
+	// When they are not set the OTEL_EXPORTER_OTLP_* environment variables are used
+	OTLPEndpoint string `yaml:"otlpEndpoint"`
+	OTLPInsecure bool   `yaml:"otlpInsecure"`
+
+	// File is where the file exporter writes the spans, one JSON document per span
+	File string `yaml:"file"`
+
+	// SampleRatio is the share of the traces started by the server that are kept,
+	// the traces started by the caller follow the caller's decision
+	SampleRatio float64 `yaml:"sampleRatio"`
+}
+
+// validate returns the problems of the tracing settings
+func (c *TracingConfig) validate() []string {
+	problems := []string{}
+
+	switch c.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		if c.File == "" {
+			problems = append(problems, "the file tracing exporter needs a file")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tracing exporter %q must be none, otlp, stdout or file", c.Exporter))
+	}
+
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		problems = append(problems, fmt.Sprintf("tracing sample ratio %v must be between 0 and 1", c.SampleRatio))
+	}
+
+	return problems
+}
+
+// SetupTracing sets the global tracer provider exporting the spans as configured, and the W3C
+// propagator reading the trace context of the calls. The returned function flushes the spans
+// not exported yet, it must be called before the server exits
+func SetupTracing(ctx context.Context, c TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// Without an exporter the spans are not recorded, the trace context is still passed on
+	if c.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var file io.Closer
+	var err error
+	switch c.Exporter {
+	case "otlp":
+		opts := []otlptracegrpc.Option{}
+		if c.OTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(c.OTLPEndpoint))
+		}
+		if c.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "file":
+		var f *os.File
+		f, err = os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening the tracing file: %w", err)
+		}
+		file = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating the %s tracing exporter: %w", c.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("civo-opencp"),
+		semconv.ServiceVersion(Version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			file.Close()
+		}
+		return err
+	}, nil
+}
+
+// TracingUnaryServerInterceptor starts a span for each unary call, continuing the trace of the caller
+func TracingUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endServerSpan(span, err)
+
+		return resp, err
+	}
+}
+
+// TracingStreamServerInterceptor starts a span for each stream call, continuing the trace of the caller
+func TracingStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		ctx, span := startServerSpan(stream.Context(), info.FullMethod)
+		wrapped.WrappedContext = ctx
+		err := handler(srv, wrapped)
+		endServerSpan(span, err)
+
+		return err
+	}
+}
+
+// startServerSpan starts the span of a call, the child of the trace context in the metadata if any
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	service, method := splitMethod(fullMethod)
+	return otel.Tracer(tracerName).Start(ctx, service+"/"+method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCService(service),
+			semconv.RPCMethod(method),
+		),
+	)
+}
+
+// endServerSpan ends the span of a call with its gRPC status
+func endServerSpan(span trace.Span, err error) {
+	s := status.Convert(CivoErrorToStatus(err))
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(s.Code())))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, s.Message())
+	}
+	span.End()
+}
+
+// CivoAPITracing is the ProviderCallHook starting a span for each Civo API call,
+// the child of the span of the gRPC call that makes it
+func CivoAPITracing() ProviderCallHook {
+	return func(ctx context.Context, endpoint string, next func() error) error {
+		_, span := otel.Tracer(tracerName).Start(ctx, "civo."+endpoint,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("civo.endpoint", endpoint)),
+		)
+		defer span.End()
+
+		err := next()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+
+		return err
+	}
+}
+
+// metadataCarrier reads and writes the trace context in the gRPC metadata
+type metadataCarrier metadata.MD
+
+// Get returns the first value of the key
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// Set replaces the values of the key
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys returns the keys of the metadata
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
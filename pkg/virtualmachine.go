@@ -2,9 +2,14 @@ package pkg
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/civo/civogo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/types"
 
 	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
@@ -12,7 +17,7 @@ import (
 )
 
 func (s *Server) ListVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachineList, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get all the virtual machines
 	allvm, err := client.ListAllInstances()
@@ -32,6 +37,18 @@ func (s *Server) ListVirtualMachine(ctx context.Context, option *opencpspec.Filt
 		return nil, err
 	}
 
+	// Get the names of the SSH keys
+	sshKeys, err := sshKeyNames(client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the names of the disk images
+	images, err := diskImageNames(client)
+	if err != nil {
+		return nil, err
+	}
+
 	// convert the virtual machines to the opencp format
 	vms := []*opencpspec.VirtualMachine{}
 	for _, vm := range allvm {
@@ -57,16 +74,17 @@ func (s *Server) ListVirtualMachine(ctx context.Context, option *opencpspec.Filt
 				Namespace:         networkName,
 				UID:               types.UID(vm.ID),
 				CreationTimestamp: metav1.Time{Time: vm.CreatedAt},
+				Annotations:       virtualMachineAnnotations(&vm),
 			},
 			Spec: &opencpspec.VirtualMachineSpec{
 				Size:     vm.Size,
 				Firewall: firewallName,
-				Ipv4:     false,
-				Ipv6:     false,
-				Image:    vm.SourceID,
+				Ipv4:     vm.PublicIP != "",
+				Ipv6:     vm.IPv6 != "",
+				Image:    nameOrID(images, vm.SourceID),
 				Auth: &opencpspec.VirtualMachineAuth{
 					User:   vm.InitialUser,
-					SshKey: vm.SSHKeyID,
+					SshKey: nameOrID(sshKeys, vm.SSHKeyID),
 				},
 				Tags:       vm.Tags,
 				UserScript: vm.Script,
@@ -103,10 +121,14 @@ func (s *Server) ListVirtualMachine(ctx context.Context, option *opencpspec.Filt
 }
 
 func (s *Server) CreateVirtualMachine(ctx context.Context, in *opencpspec.VirtualMachine) (*opencpspec.VirtualMachine, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
+
+	if err := validateVirtualMachine(in); err != nil {
+		return nil, err
+	}
 
-	// TODO move this to a GRPC util function
-	getDiskImage, err := client.FindDiskImage(in.Spec.Image)
+	// Get the disk image by its ID, its name or an alias
+	getDiskImage, err := resolveDiskImage(client, in.Spec.Image)
 	if err != nil {
 		return nil, err
 	}
@@ -117,12 +139,20 @@ func (s *Server) CreateVirtualMachine(ctx context.Context, in *opencpspec.Virtua
 		return nil, err
 	}
 
+	// civogo has no IPv6 option for a new instance, Civo gives an IPv6 address to every instance of a
+	// network with IPv6, so asking for one only checks the network of the namespace has it
+	if in.Spec.Ipv6 {
+		if err := checkIPv6(client, network); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the VM
 	vm := &civogo.InstanceConfig{
 		Hostname:         in.Metadata.Name,
 		ReverseDNS:       in.Metadata.Name,
 		Size:             in.Spec.Size,
-		Region:           client.Region,
+		Region:           client.GetRegion(),
 		PublicIPRequired: strconv.FormatBool(in.Spec.Ipv4),
 		NetworkID:        string(network.Metadata.UID),
 		TemplateID:       getDiskImage.ID,
@@ -140,12 +170,58 @@ func (s *Server) CreateVirtualMachine(ctx context.Context, in *opencpspec.Virtua
 		vm.FirewallID = string(firewall.Metadata.UID)
     }
 
-	if in.Spec.Auth.User != "" {
+	if in.Spec.Auth != nil && in.Spec.Auth.User != "" {
 		vm.InitialUser = in.Spec.Auth.User
 	}
 
-	if in.Spec.Auth.SshKey != "" {
-		vm.SSHKeyID = in.Spec.Auth.SshKey
+	// Get the SSH key by its name or its ID
+	var sshKeyName string
+	if in.Spec.Auth != nil && in.Spec.Auth.SshKey != "" {
+		sshKey, err := resolveSSHKey(client, in.Spec.Auth.SshKey)
+		if err != nil {
+			return nil, err
+		}
+		vm.SSHKeyID = sshKey.ID
+		sshKeyName = sshKey.Name
+	}
+
+	// Return the virtual machine if it was already created
+	existing, err := s.existingVirtualMachine(ctx, in, getDiskImage, sshKeyName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	// Stop before creating anything in a dry run
+	if isDryRun(ctx) {
+		if err := validateSize(client, "virtual machine", in.Spec.Size); err != nil {
+			return nil, err
+		}
+
+		return &opencpspec.VirtualMachine{
+			Metadata: &metav1.ObjectMeta{
+				Name:      in.Metadata.Name,
+				Namespace: network.Metadata.Name,
+			},
+			Spec: &opencpspec.VirtualMachineSpec{
+				Size:     in.Spec.Size,
+				Firewall: in.Spec.Firewall,
+				Ipv4:     in.Spec.Ipv4,
+				Ipv6:     in.Spec.Ipv6,
+				Image:    getDiskImage.Name,
+				Auth: &opencpspec.VirtualMachineAuth{
+					User:   vm.InitialUser,
+					SshKey: sshKeyName,
+				},
+				Tags:       in.Spec.Tags,
+				UserScript: in.Spec.UserScript,
+			},
+			Status: &opencpspec.VirtualMachineStatus{
+				State: dryRunState,
+			},
+		}, nil
 	}
 
 	// Create the VM
@@ -155,7 +231,7 @@ func (s *Server) CreateVirtualMachine(ctx context.Context, in *opencpspec.Virtua
 	}
 
 	// Get the virtual machine
-	virtualMachine, err := s.GetVirtualMachine(ctx, &opencpspec.FilterOptions{Id: &instance.ID})
+	virtualMachine, err := s.GetVirtualMachine(ctx, &opencpspec.FilterOptions{Id: &instance.ID, Namespace: &network.Metadata.Name})
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +240,7 @@ func (s *Server) CreateVirtualMachine(ctx context.Context, in *opencpspec.Virtua
 }
 
 func (s *Server) GetVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachine, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// check the options to see wish value to use
 	// TODO do a better check, put this in a util function
@@ -192,7 +268,7 @@ func (s *Server) GetVirtualMachine(ctx context.Context, option *opencpspec.Filte
 
 		// filter the virtual machine by namespace
 		if vm.NetworkID != string(network.Metadata.UID) {
-			return nil, nil
+			return nil, notFoundError("virtual machine", vm.Hostname)
 		}
 
 		// set the network name
@@ -205,22 +281,35 @@ func (s *Server) GetVirtualMachine(ctx context.Context, option *opencpspec.Filte
         return nil, err
     }
 
+	// Get the names of the SSH keys
+	sshKeys, err := sshKeyNames(client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the names of the disk images
+	images, err := diskImageNames(client)
+	if err != nil {
+		return nil, err
+	}
+
 	return &opencpspec.VirtualMachine{
 		Metadata: &metav1.ObjectMeta{
 			Name:              vm.Hostname,
 			Namespace:         networkName,
 			UID:               types.UID(vm.ID),
 			CreationTimestamp: metav1.Time{Time: vm.CreatedAt},
+			Annotations:       virtualMachineAnnotations(vm),
 		},
 		Spec: &opencpspec.VirtualMachineSpec{
 			Size:     vm.Size,
 			Firewall: firewall.Metadata.Name,
-			Ipv4:     false,
-			Ipv6:     false,
-			Image:    vm.SourceID,
+			Ipv4:     vm.PublicIP != "",
+			Ipv6:     vm.IPv6 != "",
+			Image:    nameOrID(images, vm.SourceID),
 			Auth: &opencpspec.VirtualMachineAuth{
 				User:   vm.InitialUser,
-				SshKey: vm.SSHKeyID,
+				SshKey: nameOrID(sshKeys, vm.SSHKeyID),
 			},
 			Tags:       vm.Tags,
 			UserScript: vm.Script,
@@ -234,7 +323,7 @@ func (s *Server) GetVirtualMachine(ctx context.Context, option *opencpspec.Filte
 }
 
 func (s *Server) DeleteVirtualMachine(ctx context.Context, option *opencpspec.FilterOptions) (*opencpspec.VirtualMachine, error) {
-	client := ctx.Value("client").(*civogo.Client)
+	client := ctx.Value("client").(Provider)
 
 	// Get the virtual machine
 	virtualMachine, err := s.GetVirtualMachine(ctx, option)
@@ -242,6 +331,10 @@ func (s *Server) DeleteVirtualMachine(ctx context.Context, option *opencpspec.Fi
 		return nil, err
 	}
 
+	if isDryRun(ctx) {
+		return virtualMachine, nil
+	}
+
 	// Delete the virtual machine
 	if virtualMachine != nil {
 		_, err := client.DeleteInstance(string(virtualMachine.Metadata.UID))
@@ -253,4 +346,302 @@ func (s *Server) DeleteVirtualMachine(ctx context.Context, option *opencpspec.Fi
 	return virtualMachine, nil
 }
 
-// UpdateVirtualMachine(context.Context, *VirtualMachine) (*VirtualMachine, error)
+// validateVirtualMachine checks the fields a virtual machine needs to be created
+func validateVirtualMachine(in *opencpspec.VirtualMachine) error {
+	if err := validateObjectMeta("virtual machine", in.Metadata); err != nil {
+		return err
+	}
+	if in.Spec == nil || in.Spec.Size == "" {
+		return invalidArgument("the size of virtual machine %q is required", in.Metadata.Name)
+	}
+	if in.Spec.Image == "" {
+		return invalidArgument("the image of virtual machine %q is required", in.Metadata.Name)
+	}
+
+	return nil
+}
+
+// validateResize checks that the new size is a Civo size with a disk at least as big, Civo can't shrink a disk
+func validateResize(client Provider, from, to string) error {
+	if err := validateSize(client, "virtual machine", to); err != nil {
+		return err
+	}
+
+	sizes, err := client.ListInstanceSizes()
+	if err != nil {
+		return err
+	}
+
+	disks := map[string]int{}
+	for _, size := range sizes {
+		disks[size.Name] = size.DiskGigabytes
+	}
+	if disks[to] < disks[from] {
+		return invalidArgument("size %q has a smaller disk than %q, a virtual machine can only be resized up", to, from)
+	}
+
+	return nil
+}
+
+// nameOrID returns the name of the resource with the ID, or the ID when the resource is gone
+func nameOrID(names map[string]string, id string) string {
+	if name, ok := names[id]; ok {
+		return name
+	}
+
+	return id
+}
+
+// IPv6Annotation is the annotation of a virtual machine with its public IPv6 address, the status of
+// the OpenCP virtual machines only has the IPv4 ones
+const IPv6Annotation = "opencp.io/ipv6"
+
+// virtualMachineAnnotations returns the annotations of the virtual machine of an instance
+func virtualMachineAnnotations(vm *civogo.Instance) map[string]string {
+	annotations := map[string]string{}
+	if state := powerState(vm.Status); state != "" {
+		annotations[PowerStateAnnotation] = state
+	}
+	if vm.IPv6 != "" {
+		annotations[IPv6Annotation] = vm.IPv6
+	}
+
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// checkIPv6 checks that the network of the namespace gives IPv6 addresses, not every region has IPv6
+func checkIPv6(client Provider, namespace *opencpspec.Namespace) error {
+	network, err := client.FindNetwork(string(namespace.Metadata.UID))
+	if err != nil {
+		return err
+	}
+
+	if !network.IPv6Enabled {
+		return status.Errorf(codes.FailedPrecondition, "namespace %q has no IPv6 in region %s", namespace.Metadata.Name, client.GetRegion())
+	}
+
+	return nil
+}
+
+// existingVirtualMachine returns the virtual machine with the name of in in its namespace, nil if there is none,
+// or AlreadyExists if it does not match in. The instance is looked up by its hostname, so only a retried create
+// pays for the lookups of the whole virtual machine
+func (s *Server) existingVirtualMachine(ctx context.Context, in *opencpspec.VirtualMachine, image *civogo.DiskImage, sshKeyName string) (*opencpspec.VirtualMachine, error) {
+	client := ctx.Value("client").(Provider)
+
+	// FindInstance also matches part of the hostnames, only the exact one is the virtual machine
+	instance, err := client.FindInstance(in.Metadata.Name)
+	if errors.Is(err, civogo.ZeroMatchesError) || errors.Is(err, civogo.MultipleMatchesError) || (err == nil && instance.Hostname != in.Metadata.Name) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	network, err := client.FindNetwork(instance.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	vm, err := s.GetVirtualMachine(ctx, &opencpspec.FilterOptions{Id: &instance.ID, Namespace: &network.Label})
+	if err != nil {
+		return nil, err
+	}
+
+	return existingResource("virtual machine", in.Metadata, []*opencpspec.VirtualMachine{vm}, func(vm *opencpspec.VirtualMachine) specDiff {
+		diff := specDiff{}
+		diff.compare("size", in.Spec.Size, vm.Spec.Size)
+		if vm.Spec.Image != image.Name {
+			diff = append(diff, "image")
+		}
+		diff.compare("firewall", in.Spec.Firewall, vm.Spec.Firewall)
+		if (in.Spec.Ipv4 && !vm.Spec.Ipv4) || (in.Spec.Ipv6 && !vm.Spec.Ipv6) {
+			diff = append(diff, "addressing")
+		}
+		if in.Spec.Auth != nil {
+			diff.compare("user", in.Spec.Auth.User, vm.Spec.Auth.User)
+		}
+		diff.compare("sshKey", sshKeyName, vm.Spec.Auth.SshKey)
+		if len(in.Spec.Tags) > 0 && !sameTags(in.Spec.Tags, vm.Spec.Tags) {
+			diff = append(diff, "tags")
+		}
+		diff.compare("userScript", in.Spec.UserScript, vm.Spec.UserScript)
+		return diff
+	})
+}
+
+// sameTags returns true if the tags are the same, in any order
+func sameTags(a, b []string) bool {
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return strings.Join(a, " ") == strings.Join(b, " ")
+}
+
+// RebuildMetadataKey is the metadata key allowing an update to rebuild a virtual machine from a new image, e.g. true.
+// A rebuild wipes the disk of the virtual machine
+const RebuildMetadataKey = "x-opencp-rebuild"
+
+// UpdateVirtualMachine changes the size, the tags, the firewall and the image of a virtual machine to the ones of in,
+// the fields left empty, the tags included, are not changed, and gets it to the power state of its annotation. The virtual machine is
+// found by its UID, or by its name in its namespace when it has one
+func (s *Server) UpdateVirtualMachine(ctx context.Context, in *opencpspec.VirtualMachine) (*opencpspec.VirtualMachine, error) {
+	client := ctx.Value("client").(Provider)
+
+	if in.Metadata == nil || (in.Metadata.Name == "" && in.Metadata.UID == "") {
+		return nil, invalidArgument("the name or the UID of the virtual machine is required")
+	}
+	spec := in.Spec
+	if spec == nil {
+		spec = &opencpspec.VirtualMachineSpec{}
+	}
+
+	// Get the virtual machine with its namespace
+	vms, err := s.ListVirtualMachine(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*opencpspec.VirtualMachine{}
+	for _, vm := range vms.Items {
+		if in.Metadata.UID != "" {
+			if vm.Metadata.UID == in.Metadata.UID {
+				matches = append(matches, vm)
+			}
+		} else if vm.Metadata.Name == in.Metadata.Name && (in.Metadata.Namespace == "" || vm.Metadata.Namespace == in.Metadata.Namespace) {
+			matches = append(matches, vm)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, notFoundError("virtual machine", in.Metadata.Name)
+	}
+	if len(matches) > 1 {
+		return nil, invalidArgument("%d virtual machines are named %q, set the namespace or the UID", len(matches), in.Metadata.Name)
+	}
+	current := matches[0]
+
+	// Reject the fields Civo can't change on a running virtual machine
+	immutable := specDiff{}
+	immutable.compare("name", in.Metadata.Name, current.Metadata.Name)
+	immutable.compare("namespace", in.Metadata.Namespace, current.Metadata.Namespace)
+	if spec.Auth != nil {
+		immutable.compare("user", spec.Auth.User, current.Spec.Auth.User)
+		if spec.Auth.SshKey != "" && spec.Auth.SshKey != current.Spec.Auth.SshKey {
+			sshKey, err := resolveSSHKey(client, spec.Auth.SshKey)
+			if err != nil {
+				return nil, err
+			}
+			immutable.compare("sshKey", sshKey.Name, current.Spec.Auth.SshKey)
+		}
+	}
+	immutable.compare("userScript", spec.UserScript, current.Spec.UserScript)
+	if spec.Ipv4 && !current.Spec.Ipv4 {
+		immutable = append(immutable, "ipv4")
+	}
+	if spec.Ipv6 && !current.Spec.Ipv6 {
+		immutable = append(immutable, "ipv6")
+	}
+	if len(immutable) > 0 {
+		return nil, invalidArgument("%s of virtual machine %q can't be changed", strings.Join(immutable, ", "), current.Metadata.Name)
+	}
+
+	updated := *current.Spec
+	uid := string(current.Metadata.UID)
+
+	powerState := in.Metadata.Annotations[PowerStateAnnotation]
+	if powerState != "" {
+		if err := checkPowerState(current, powerState); err != nil {
+			return nil, err
+		}
+	}
+
+	// A new image rebuilds the virtual machine, only when the caller asks for it
+	var image *civogo.DiskImage
+	if spec.Image != "" && spec.Image != current.Spec.Image {
+		image, err = resolveDiskImage(client, spec.Image)
+		if err != nil {
+			return nil, err
+		}
+
+		if image.Name == current.Spec.Image {
+			image = nil
+		} else if !metadataFlag(ctx, RebuildMetadataKey) {
+			return nil, status.Errorf(codes.FailedPrecondition, "changing the image of virtual machine %q rebuilds it and wipes its disk, set the %s metadata to true to allow it", current.Metadata.Name, RebuildMetadataKey)
+		} else {
+			updated.Image = image.Name
+		}
+	}
+
+	// The new firewall must be in the namespace of the virtual machine
+	var firewall *opencpspec.Firewall
+	if spec.Firewall != "" && spec.Firewall != current.Spec.Firewall {
+		firewall, err = s.GetFirewall(ctx, &opencpspec.FilterOptions{Name: &spec.Firewall, Namespace: &current.Metadata.Namespace})
+		if err != nil {
+			return nil, err
+		}
+		updated.Firewall = firewall.Metadata.Name
+	}
+
+	resize := spec.Size != "" && spec.Size != current.Spec.Size
+	if resize {
+		if err := validateResize(client, current.Spec.Size, spec.Size); err != nil {
+			return nil, err
+		}
+		updated.Size = spec.Size
+	}
+
+	// No tags leave the tags as they are, so they can't be cleared by an update
+	retag := len(spec.Tags) > 0 && !sameTags(spec.Tags, current.Spec.Tags)
+	if retag {
+		updated.Tags = spec.Tags
+	}
+
+	// Stop before changing anything in a dry run
+	if isDryRun(ctx) {
+		return &opencpspec.VirtualMachine{
+			Metadata: current.Metadata,
+			Spec:     &updated,
+			Status: &opencpspec.VirtualMachineStatus{
+				PrivateIP: current.Status.PrivateIP,
+				PublicIP:  current.Status.PublicIP,
+				State:     dryRunState,
+			},
+		}, nil
+	}
+
+	if firewall != nil {
+		if _, err := client.SetInstanceFirewall(uid, string(firewall.Metadata.UID)); err != nil {
+			return nil, err
+		}
+	}
+
+	if retag {
+		if _, err := client.SetInstanceTags(&civogo.Instance{ID: uid}, strings.Join(spec.Tags, " ")); err != nil {
+			return nil, err
+		}
+	}
+
+	if resize {
+		if _, err := client.UpgradeInstance(uid, spec.Size); err != nil {
+			return nil, err
+		}
+	}
+
+	if image != nil {
+		Logger(ctx).WithField("image", image.Name).Info("rebuilding the virtual machine")
+		if _, err := client.RebuildInstance(uid, image.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if powerState != "" {
+		if err := applyPowerState(client, current, powerState); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetVirtualMachine(ctx, &opencpspec.FilterOptions{Id: &uid, Namespace: &current.Metadata.Namespace})
+}
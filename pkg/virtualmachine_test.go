@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"testing"
+
+	opencpspec "github.com/opencontrolplane/opencp-spec/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCreateVirtualMachineRetried(t *testing.T) {
+	virtualMachines := opencpspec.NewVirtualMachineServiceClient(newTestConn(t))
+	ctx := withToken("retry-token")
+
+	vm := createTestVirtualMachine(t, ctx, virtualMachines, "vm1")
+
+	again, err := virtualMachines.CreateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{Name: "vm1", Namespace: "default"},
+		Spec:     &opencpspec.VirtualMachineSpec{Size: "g3.small", Image: "ubuntu-jammy", Ipv4: true},
+	})
+	if err != nil || again.Metadata.UID != vm.Metadata.UID {
+		t.Fatalf("creating the same virtual machine again: got %v, %v, want the first one", again, err)
+	}
+
+	_, err = virtualMachines.CreateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{Name: "vm1", Namespace: "default"},
+		Spec:     &opencpspec.VirtualMachineSpec{Size: "g3.large", Image: "ubuntu-jammy", Ipv4: true},
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("creating a different virtual machine with the same name: got %v, want AlreadyExists", err)
+	}
+
+	namespace := "default"
+	if list, err := virtualMachines.ListVirtualMachine(ctx, &opencpspec.FilterOptions{Namespace: &namespace}); err != nil || len(list.Items) != 1 {
+		t.Fatalf("listing the virtual machines: %v, %v, want only the first one", list, err)
+	}
+}
+
+func TestUpdateVirtualMachine(t *testing.T) {
+	virtualMachines := opencpspec.NewVirtualMachineServiceClient(newTestConn(t))
+	ctx := withToken("update-token")
+
+	vm := createTestVirtualMachine(t, ctx, virtualMachines, "vm1")
+
+	rebuild := &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{UID: vm.Metadata.UID},
+		Spec:     &opencpspec.VirtualMachineSpec{Image: "debian:11", Tags: []string{"web", "prod"}},
+	}
+	if _, err := virtualMachines.UpdateVirtualMachine(ctx, rebuild); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("changing the image without %s: got %v, want FailedPrecondition", RebuildMetadataKey, err)
+	}
+
+	rebuilt, err := virtualMachines.UpdateVirtualMachine(metadata.AppendToOutgoingContext(ctx, RebuildMetadataKey, "true"), rebuild)
+	if err != nil {
+		t.Fatalf("rebuilding the virtual machine: %v", err)
+	}
+	if rebuilt.Spec.Image != "debian-11" || !sameTags(rebuilt.Spec.Tags, []string{"prod", "web"}) {
+		t.Fatalf("rebuilt virtual machine has image %q and tags %v, want debian-11 and web, prod", rebuilt.Spec.Image, rebuilt.Spec.Tags)
+	}
+
+	// The same tags in another order are no change, the lookup by name stays in the namespace of the call
+	if _, err := virtualMachines.UpdateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{Name: "vm1", Namespace: "default"},
+		Spec:     &opencpspec.VirtualMachineSpec{Tags: []string{"prod", "web"}},
+	}); err != nil {
+		t.Fatalf("updating the virtual machine by name: %v", err)
+	}
+	if _, err := virtualMachines.UpdateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+		Metadata: &metav1.ObjectMeta{Name: "vm1", Namespace: "other"},
+		Spec:     &opencpspec.VirtualMachineSpec{Size: "g3.large"},
+	}); status.Code(err) != codes.NotFound {
+		t.Fatalf("updating the virtual machine by name in another namespace: got %v, want NotFound", err)
+	}
+}
+
+func TestCreateVirtualMachineRetriedWithTagsInAnotherOrder(t *testing.T) {
+	virtualMachines := opencpspec.NewVirtualMachineServiceClient(newTestConn(t))
+	ctx := withToken("tags-token")
+
+	for _, tags := range [][]string{{"web", "prod"}, {"prod", "web"}} {
+		if _, err := virtualMachines.CreateVirtualMachine(ctx, &opencpspec.VirtualMachine{
+			Metadata: &metav1.ObjectMeta{Name: "vm1", Namespace: "default"},
+			Spec:     &opencpspec.VirtualMachineSpec{Size: "g3.small", Image: "ubuntu-jammy", Ipv4: true, Tags: tags},
+		}); err != nil {
+			t.Fatalf("creating the virtual machine with the tags %v: %v", tags, err)
+		}
+	}
+}